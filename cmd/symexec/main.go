@@ -0,0 +1,459 @@
+// Команда symexec выполняет символьное исполнение функций из Go-файла или пакетов модуля
+// и печатает найденные пути, их условия и модели входных данных.
+//
+// Использование:
+//
+//	symexec [флаги] <файл.go | каталог | шаблон пакетов> <функция | регулярное выражение>
+//
+// Одиночный файл разбирается без импортов. Каталоги и шаблоны пакетов (например, ./...)
+// загружаются вместе с зависимостями, а функции можно указывать полным именем
+// вида pkg/path.Type.Method
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "symexec: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// functionReport описывает результат анализа одной функции
+type functionReport struct {
+	Function string       `json:"function"`
+	Steps    int          `json:"steps"`
+	Error    string       `json:"error,omitempty"`
+	Paths    []pathReport `json:"paths"`
+	Panics   []pathReport `json:"panics,omitempty"`
+	Aborted  []pathReport `json:"aborted,omitempty"`
+
+	RuntimeErrors []runtimeErrorReport `json:"runtimeErrors,omitempty"`
+	Solver        *solverReport        `json:"solver,omitempty"`
+}
+
+// solverReport описывает обращения к решателю при анализе функции
+type solverReport struct {
+	Queries     int    `json:"queries"`
+	Partitions  int    `json:"partitions"`
+	Solved      int    `json:"solved"`
+	CacheHits   int    `json:"cacheHits"`
+	ExactHits   int    `json:"exactHits"`
+	SubsetHits  int    `json:"subsetHits"`
+	ModelHits   int    `json:"modelHits"`
+	CacheMisses int    `json:"cacheMisses"`
+	TimeSaved   string `json:"timeSaved"`
+}
+
+// pathReport описывает один завершённый путь
+type pathReport struct {
+	PathCondition string       `json:"pathCondition"`
+	ReturnValue   string       `json:"returnValue,omitempty"`
+	PanicValue    string       `json:"panicValue,omitempty"`
+	AbortReason   string       `json:"abortReason,omitempty"`
+	Solved        bool         `json:"solved"`
+	Setup         []string     `json:"setup,omitempty"`
+	Inputs        []inputValue `json:"inputs,omitempty"`
+}
+
+// runtimeErrorReport описывает достижимую панику среды исполнения
+type runtimeErrorReport struct {
+	Kind          string       `json:"kind"`
+	Position      string       `json:"position"`
+	Instruction   string       `json:"instruction"`
+	PathCondition string       `json:"pathCondition"`
+	Setup         []string     `json:"setup,omitempty"`
+	Inputs        []inputValue `json:"inputs"`
+}
+
+// inputValue описывает значение параметра в модели условия пути
+type inputValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("symexec", flag.ContinueOnError)
+	selector := flags.String("selector", "dfs", "стратегия выбора пути: dfs, bfs или random")
+	steps := flags.Int("steps", internal.DefaultStepLimit, "максимальное число шагов анализа одной функции")
+	timeout := flags.Duration("timeout", internal.DefaultTimeLimit, "максимальное время анализа одной функции")
+	callDepth := flags.Int("call-depth", internal.DefaultMaxCallDepth, "максимальная глубина стека вызовов")
+	calls := flags.String("calls", "inline", "исполнение вызовов: inline или summary")
+	assume := flags.String("assume", "", "имя функции-допущения, аргумент которой добавляется к условию пути")
+	format := flags.String("format", "text", "формат вывода: text, json или test")
+	smtDir := flags.String("smt2", "", "каталог, в который записывается запрос SMT-LIB2 для каждого найденного пути")
+	stats := flags.Bool("stats", false, "печатать статистику обращений к решателю и кэша запросов")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Использование: symexec [флаги] <файл.go | каталог | шаблон пакетов> <функция | регулярное выражение>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("ожидались путь к коду и имя функции")
+	}
+
+	candidates, err := loadFunctions(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	functions, err := matchFunctions(candidates, flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *format == "test" && len(functions) != 1 {
+		return fmt.Errorf("формат test требует ровно одну функцию, найдено %d", len(functions))
+	}
+
+	var reports []functionReport
+	for _, function := range functions {
+		pathSelector, err := newPathSelector(*selector)
+		if err != nil {
+			return err
+		}
+		stoppingStrategy := &internal.AnyOfStrategy{Strategies: []internal.StoppingStrategy{
+			&internal.StepLimitStrategy{Limit: *steps},
+			&internal.TimeLimitStrategy{Limit: *timeout},
+		}}
+
+		analyser := internal.NewAnalyser(pathSelector, stoppingStrategy)
+		analyser.MaxCallDepth = *callDepth
+		analyser.DefaultCallMode, err = parseCallMode(*calls)
+		if err != nil {
+			return err
+		}
+		if *assume != "" {
+			assumeFunction := function.Pkg.Func(*assume)
+			if assumeFunction == nil {
+				return fmt.Errorf("функция-допущение %s не найдена в пакете %s", *assume, function.Pkg.Pkg.Path())
+			}
+			analyser.AssumeFunctions[assumeFunction] = true
+		}
+		err = analyse(analyser, function)
+		if *smtDir != "" {
+			if dumpErr := dumpSmtLib(*smtDir, analyser); dumpErr != nil {
+				return dumpErr
+			}
+		}
+
+		if *format == "test" {
+			if err != nil {
+				return err
+			}
+			source, err := internal.GenerateTestFile(function, analyser.GenerateTestCases())
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(source)
+			return err
+		}
+		functionReport := report(analyser)
+		if err != nil {
+			functionReport.Error = err.Error()
+		}
+		if *stats {
+			functionReport.Solver = solverReportOf(analyser)
+		}
+		reports = append(reports, functionReport)
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case "text":
+		printReports(out, reports)
+		return nil
+	default:
+		return fmt.Errorf("неизвестный формат вывода %q", *format)
+	}
+}
+
+// analyse запускает анализ функции. Неподдерживаемые интерпретатором конструкции
+// приводят к панике, которая превращается в ошибку, чтобы не прерывать анализ остальных функций
+func analyse(analyser *internal.Analyser, function *ssa.Function) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("анализ %s прерван: %v", function.Name(), recovered)
+		}
+	}()
+
+	analyser.AnalyseFunction(function)
+	return nil
+}
+
+// loadFunctions строит SSA одного файла или загружает пакеты с диска
+// и возвращает функции, доступные для анализа
+func loadFunctions(path string) ([]*ssa.Function, error) {
+	builder := ssabuilder.NewBuilder()
+
+	if strings.HasSuffix(path, ".go") {
+		pkg, err := builder.ParseAndBuildPackage(path)
+		if err != nil {
+			return nil, err
+		}
+		var functions []*ssa.Function
+		for _, member := range pkg.Members {
+			if function, ok := member.(*ssa.Function); ok && function.Synthetic == "" {
+				functions = append(functions, function)
+			}
+		}
+		return functions, nil
+	}
+
+	dir, pattern := ".", path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dir, pattern = path, "."
+	}
+	program, err := builder.LoadPackages(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return program.Functions(), nil
+}
+
+// matchFunctions возвращает функции, полное имя или имя внутри пакета которых целиком совпадает с pattern
+func matchFunctions(candidates []*ssa.Function, pattern string) ([]*ssa.Function, error) {
+	matcher, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	var functions []*ssa.Function
+	for _, function := range candidates {
+		name := ssabuilder.QualifiedName(function)
+		shortName := strings.TrimPrefix(name, function.Pkg.Pkg.Path()+".")
+		// Методы с указателем на получателя можно указывать и без скобок: Type.Method
+		plainName := strings.NewReplacer("(*", "", ")", "").Replace(shortName)
+		if len(function.Blocks) == 0 {
+			continue
+		}
+		if matcher.MatchString(name) || matcher.MatchString(shortName) || matcher.MatchString(plainName) {
+			functions = append(functions, function)
+		}
+	}
+	if len(functions) == 0 {
+		return nil, fmt.Errorf("функции, соответствующие %q, не найдены", pattern)
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		return ssabuilder.QualifiedName(functions[i]) < ssabuilder.QualifiedName(functions[j])
+	})
+	return functions, nil
+}
+
+func newPathSelector(name string) (internal.PathSelector, error) {
+	switch name {
+	case "dfs":
+		return &internal.DfsPathSelector{}, nil
+	case "bfs":
+		return &internal.BfsPathSelector{}, nil
+	case "random":
+		return &internal.RandomPathSelector{}, nil
+	default:
+		return nil, fmt.Errorf("неизвестная стратегия выбора пути %q", name)
+	}
+}
+
+func parseCallMode(name string) (internal.CallMode, error) {
+	switch name {
+	case "inline":
+		return internal.InlineCall, nil
+	case "summary":
+		return internal.SummaryCall, nil
+	default:
+		return 0, fmt.Errorf("неизвестный способ исполнения вызовов %q", name)
+	}
+}
+
+func report(analyser *internal.Analyser) functionReport {
+	result := functionReport{Function: ssabuilder.QualifiedName(analyser.Function), Steps: analyser.Steps}
+	for i, state := range analyser.Results {
+		result.Paths = append(result.Paths, pathReportOf(analyser, state, fmt.Sprintf("path_%d", i+1)))
+	}
+	for i, state := range analyser.Panics {
+		result.Panics = append(result.Panics, pathReportOf(analyser, state, fmt.Sprintf("panic_%d", i+1)))
+	}
+	for i, state := range analyser.Aborted {
+		result.Aborted = append(result.Aborted, pathReportOf(analyser, state, fmt.Sprintf("aborted_%d", i+1)))
+	}
+
+	for _, runtimeError := range analyser.Errors {
+		result.RuntimeErrors = append(result.RuntimeErrors, runtimeErrorReport{
+			Kind:          runtimeError.Kind.String(),
+			Position:      runtimeError.Position.String(),
+			Instruction:   runtimeError.Instruction,
+			PathCondition: runtimeError.State.PathCondition.String(),
+			Setup:         runtimeError.TestCase.Setup,
+			Inputs:        inputValues(analyser.Function, runtimeError.TestCase),
+		})
+	}
+	return result
+}
+
+// solverReportOf собирает статистику решателя. Вызывается после построения моделей путей,
+// чтобы учесть и запросы генерации тестов
+func solverReportOf(analyser *internal.Analyser) *solverReport {
+	independence := analyser.Solver.Stats()
+	cache := analyser.Solver.Cache.Stats()
+	return &solverReport{
+		Queries:     independence.Queries,
+		Partitions:  independence.Partitions,
+		Solved:      independence.Solved,
+		CacheHits:   cache.Hits,
+		ExactHits:   cache.Exact,
+		SubsetHits:  cache.Subset,
+		ModelHits:   cache.Model,
+		CacheMisses: cache.Misses,
+		TimeSaved:   cache.Saved.Round(time.Microsecond).String(),
+	}
+}
+
+func pathReportOf(analyser *internal.Analyser, state internal.Interpreter, name string) pathReport {
+	path := pathReport{PathCondition: state.PathCondition.String(), PanicValue: state.PanicValue, AbortReason: state.AbortReason}
+	if returnValue := state.CallStack[0].ReturnValue; returnValue != nil {
+		path.ReturnValue = symbolic.Simplify(returnValue).String()
+	}
+
+	if testCase, ok := analyser.GenerateTestCase(state, name); ok {
+		path.Solved = true
+		path.Setup = testCase.Setup
+		path.Inputs = inputValues(analyser.Function, testCase)
+	}
+	return path
+}
+
+// dumpSmtLib записывает условие каждого пути, паники и ошибки времени исполнения
+// в отдельный файл SMT-LIB2, который можно решить внешним решателем
+func dumpSmtLib(dir string, analyser *internal.Analyser) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	name := ssabuilder.QualifiedName(analyser.Function)
+	prefix := strings.Map(func(r rune) rune {
+		if r == '/' || r == '*' || r == '(' || r == ')' {
+			return '_'
+		}
+		return r
+	}, name)
+	write := func(kind string, i int, pathCondition *symbolic.PathConstraints) error {
+		script, err := translator.NewSmtLibTranslator().Script(pathCondition.Expression())
+		if err != nil {
+			return fmt.Errorf("%s %s_%d: %w", name, kind, i, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s_%d.smt2", prefix, kind, i))
+		return os.WriteFile(path, []byte(fmt.Sprintf("; %s, %s_%d\n%s", name, kind, i, script)), 0644)
+	}
+
+	for i, state := range analyser.Results {
+		if err := write("path", i+1, state.PathCondition); err != nil {
+			return err
+		}
+	}
+	for i, state := range analyser.Panics {
+		if err := write("panic", i+1, state.PathCondition); err != nil {
+			return err
+		}
+	}
+	for i, runtimeError := range analyser.Errors {
+		if err := write("error", i+1, runtimeError.State.PathCondition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inputValues(function *ssa.Function, testCase internal.TestCase) []inputValue {
+	inputs := make([]inputValue, len(function.Params))
+	for i, param := range function.Params {
+		inputs[i] = inputValue{Name: param.Name(), Value: testCase.Arguments[i]}
+	}
+	return inputs
+}
+
+func printReports(out io.Writer, reports []functionReport) {
+	for _, report := range reports {
+		fmt.Fprintf(out, "Функция %s: путей %d, шагов %d", report.Function, len(report.Paths), report.Steps)
+		if len(report.Aborted) > 0 {
+			fmt.Fprintf(out, ", прервано путей %d", len(report.Aborted))
+		}
+		fmt.Fprintln(out)
+		if report.Error != "" {
+			fmt.Fprintf(out, "  Ошибка: %s\n", report.Error)
+		}
+		for i, path := range report.Paths {
+			printPath(out, fmt.Sprintf("Путь %d", i+1), path)
+		}
+		for i, path := range report.Panics {
+			printPath(out, fmt.Sprintf("Паника %d", i+1), path)
+		}
+		for i, path := range report.Aborted {
+			printPath(out, fmt.Sprintf("Прерванный путь %d", i+1), path)
+		}
+		for i, runtimeError := range report.RuntimeErrors {
+			fmt.Fprintf(out, "  Ошибка времени исполнения %d: %s\n", i+1, runtimeError.Kind)
+			fmt.Fprintf(out, "    Позиция: %s\n", runtimeError.Position)
+			fmt.Fprintf(out, "    Инструкция: %s\n", runtimeError.Instruction)
+			fmt.Fprintf(out, "    Условие: %s\n", runtimeError.PathCondition)
+			printModel(out, runtimeError.Setup, runtimeError.Inputs)
+		}
+		if solver := report.Solver; solver != nil {
+			fmt.Fprintf(out, "  Решатель: запросов %d, независимых частей %d, решено %d\n", solver.Queries, solver.Partitions, solver.Solved)
+			fmt.Fprintf(out, "  Кэш запросов: попаданий %d (точных %d, по невыполнимым подмножествам %d, по моделям %d), промахов %d, сэкономлено %s\n",
+				solver.CacheHits, solver.ExactHits, solver.SubsetHits, solver.ModelHits, solver.CacheMisses, solver.TimeSaved)
+		}
+	}
+}
+
+func printPath(out io.Writer, title string, path pathReport) {
+	fmt.Fprintf(out, "  %s\n", title)
+	fmt.Fprintf(out, "    Условие: %s\n", path.PathCondition)
+	if path.ReturnValue != "" {
+		fmt.Fprintf(out, "    Результат: %s\n", path.ReturnValue)
+	}
+	if path.PanicValue != "" {
+		fmt.Fprintf(out, "    Значение паники: %s\n", path.PanicValue)
+	}
+	if path.AbortReason != "" {
+		fmt.Fprintf(out, "    Причина: %s\n", path.AbortReason)
+	}
+	if !path.Solved {
+		fmt.Fprintln(out, "    Модель не найдена")
+		return
+	}
+	printModel(out, path.Setup, path.Inputs)
+}
+
+func printModel(out io.Writer, setup []string, inputs []inputValue) {
+	for _, line := range setup {
+		fmt.Fprintf(out, "    %s\n", line)
+	}
+	values := make([]string, len(inputs))
+	for i, input := range inputs {
+		values[i] = input.Name + " = " + input.Value
+	}
+	fmt.Fprintf(out, "    Модель: %s\n", strings.Join(values, ", "))
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSource = `package sample
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func Sign(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func Div(a, b int) int {
+	return a / b
+}
+
+func assume(condition bool) {}
+
+func Checked(n int) int {
+	if n > 10 {
+		panic("n is too large")
+	}
+	return n
+}
+
+func Assumed(n int) int {
+	assume(n <= 10)
+	return Checked(n)
+}
+`
+
+func writeSource(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sample.go")
+	if err := os.WriteFile(path, []byte(testSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRunText тестирует текстовый вывод для функций, выбранных регулярным выражением
+func TestRunText(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-selector", "bfs", writeSource(t), "A.*|Sign"}, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	output := out.String()
+	for _, expected := range []string{"Функция sample.Abs: путей 2", "Функция sample.Sign: путей 2", "Модель: x = "} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+// TestRunJSON тестирует вывод в формате JSON
+func TestRunJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-format", "json", writeSource(t), "Abs"}, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	var reports []functionReport
+	if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(reports) != 1 || len(reports[0].Paths) != 2 {
+		t.Fatalf("Expected one function with 2 paths, got %+v", reports)
+	}
+	for _, path := range reports[0].Paths {
+		if !path.Solved || len(path.Inputs) != 1 {
+			t.Errorf("Expected a model for path %+v", path)
+		}
+	}
+}
+
+// TestRunRuntimeErrors тестирует вывод найденных паник среды исполнения
+func TestRunRuntimeErrors(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-format", "json", writeSource(t), "Div"}, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	var reports []functionReport
+	if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(reports) != 1 || len(reports[0].RuntimeErrors) != 1 {
+		t.Fatalf("Expected one function with a runtime error, got %+v", reports)
+	}
+	runtimeError := reports[0].RuntimeErrors[0]
+	if runtimeError.Kind != "integer divide by zero" || !strings.HasSuffix(runtimeError.Position, "sample.go:18:11") {
+		t.Errorf("Unexpected runtime error %+v", runtimeError)
+	}
+	if len(runtimeError.Inputs) != 2 || runtimeError.Inputs[1].Value != "0" {
+		t.Errorf("Expected b = 0 in the model, got %+v", runtimeError.Inputs)
+	}
+}
+
+// TestRunPanics тестирует вывод путей с паникой и функцию-допущение
+func TestRunPanics(t *testing.T) {
+	path := writeSource(t)
+	for _, tt := range []struct {
+		args   []string
+		panics int
+	}{
+		{[]string{"-format", "json", path, "Assumed"}, 1},
+		{[]string{"-format", "json", "-assume", "assume", path, "Assumed"}, 0},
+	} {
+		var out bytes.Buffer
+		if err := run(tt.args, &out); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+
+		var reports []functionReport
+		if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		if len(reports) != 1 || len(reports[0].Panics) != tt.panics {
+			t.Fatalf("Expected %d panics for %v, got %+v", tt.panics, tt.args, reports)
+		}
+		for _, panicPath := range reports[0].Panics {
+			if panicPath.PanicValue != "n is too large" || !panicPath.Solved {
+				t.Errorf("Unexpected panic path %+v", panicPath)
+			}
+		}
+	}
+}
+
+// TestRunErrors тестирует ошибки в аргументах командной строки
+func TestRunErrors(t *testing.T) {
+	path := writeSource(t)
+	for _, args := range [][]string{
+		{path},
+		{path, "Missing"},
+		{"-selector", "unknown", path, "Abs"},
+		{"-format", "test", path, ".*"},
+		{"-assume", "missing", path, "Abs"},
+	} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("Expected error for arguments %v", args)
+		}
+	}
+}
+
+// TestRunSmtLib тестирует запись запросов SMT-LIB2 для найденных путей
+func TestRunSmtLib(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queries")
+	if err := run([]string{"-smt2", dir, writeSource(t), "Abs|Div"}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.smt2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Два пути Abs, путь Div и деление на ноль в Div
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 queries, got %v", files)
+	}
+
+	query, err := os.ReadFile(filepath.Join(dir, "sample.Abs.path_1.smt2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"(declare-const x (_ BitVec 64))", "(assert ", "(check-sat)"} {
+		if !strings.Contains(string(query), expected) {
+			t.Errorf("Expected query to contain %q, got:\n%s", expected, query)
+		}
+	}
+}
+
+// TestRunStats тестирует вывод статистики решателя и кэша запросов
+func TestRunStats(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-stats", "-format", "json", writeSource(t), "Abs"}, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	var reports []functionReport
+	if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	solver := reports[0].Solver
+	if solver == nil || solver.Queries == 0 || solver.CacheHits+solver.CacheMisses != solver.Partitions {
+		t.Errorf("Expected solver statistics, got %+v", solver)
+	}
+
+	out.Reset()
+	if err := run([]string{"-stats", writeSource(t), "Abs"}, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Кэш запросов: попаданий") {
+		t.Errorf("Expected cache statistics in output, got:\n%s", out.String())
+	}
+}
@@ -1,19 +1,151 @@
 package internal
 
 import (
+	"container/heap"
+	"fmt"
+	"time"
+
 	"golang.org/x/tools/go/ssa"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
 	"symbolic-execution-course/internal/translator"
 )
 
 type Analyser struct {
-	Package      *ssa.Package
-	StatesQueue  PriorityQueue
-	PathSelector PathSelector
-	Results      []Interpreter
-	Z3Translator *translator.Z3Translator
+	Package          *ssa.Package
+	Function         *ssa.Function
+	StatesQueue      PriorityQueue
+	PathSelector     PathSelector
+	StoppingStrategy StoppingStrategy
+	Results          []Interpreter
+	Panics           []Interpreter
+	Aborted          []Interpreter
+	Errors           []RuntimeError
+	Z3Translator     *translator.Z3Translator
+	Solver           *IndependentSolver
+	MaxCallDepth     int
+
+	// Expressions интернирует значения регистров, чтобы состояния
+	// и конъюнкты их условий путей разделяли общие подвыражения
+	Expressions *symbolic.ExpressionFactory
+
+	// Strings — текст строковых констант по их непрозрачным значениям
+	Strings map[int64]string
+
+	// Способ исполнения вызовов: по умолчанию и для отдельных функций
+	DefaultCallMode  CallMode
+	CallModes        map[*ssa.Function]CallMode
+	Summaries        *SummaryCache
+	SummaryStepLimit int
+
+	// AssumeFunctions — функции-допущения: вызов такой функции не исполняется,
+	// а добавляет её аргумент к условию пути
+	AssumeFunctions map[*ssa.Function]bool
+
+	// Статистика текущего запуска, используемая стратегиями остановки
+	Steps         int
+	StartTime     time.Time
+	CoveredBlocks map[*ssa.BasicBlock]bool
 }
 
+// NewAnalyser создаёт анализатор с заданными стратегиями выбора пути и остановки
+func NewAnalyser(pathSelector PathSelector, stoppingStrategy StoppingStrategy) *Analyser {
+	z3Translator := translator.NewZ3Translator()
+	return &Analyser{
+		PathSelector:     pathSelector,
+		StoppingStrategy: stoppingStrategy,
+		Z3Translator:     z3Translator,
+		Solver:           NewIndependentSolver(z3Translator, NewCounterexampleCache(z3Translator)),
+		Expressions:      symbolic.NewExpressionFactory(),
+		Strings:          make(map[int64]string),
+		MaxCallDepth:     DefaultMaxCallDepth,
+		CallModes:        make(map[*ssa.Function]CallMode),
+		Summaries:        NewSummaryCache(),
+		SummaryStepLimit: DefaultSummaryStepLimit,
+		AssumeFunctions:  make(map[*ssa.Function]bool),
+		CoveredBlocks:    make(map[*ssa.BasicBlock]bool),
+	}
+}
+
+// Analyse исследует пути функции functionName из исходного кода source
+// с DFS и стратегией остановки по умолчанию
 func Analyse(source string, functionName string) []Interpreter {
-	// TODO implement me
-	panic("implement me")
+	return AnalyseWith(source, functionName, &DfsPathSelector{}, DefaultStoppingStrategy())
+}
+
+// AnalyseWith исследует пути функции functionName с заданными стратегиями
+func AnalyseWith(source string, functionName string, pathSelector PathSelector, stoppingStrategy StoppingStrategy) []Interpreter {
+	builder := ssabuilder.NewBuilder()
+	function, err := builder.ParseAndBuildSSA(source, functionName)
+	if err != nil {
+		panic(fmt.Sprintf("Ошибка построения SSA: %v", err))
+	}
+	if function == nil {
+		panic(fmt.Sprintf("Функция %s не найдена", functionName))
+	}
+
+	return NewAnalyser(pathSelector, stoppingStrategy).AnalyseFunction(function)
+}
+
+// AnalyseFunction выполняет основной цикл анализа: достаёт состояние из очереди,
+// делает шаг интерпретации и складывает полученные состояния обратно,
+// пока очередь не опустеет или не сработает стратегия остановки
+func (analyser *Analyser) AnalyseFunction(function *ssa.Function) []Interpreter {
+	if len(function.Blocks) == 0 {
+		panic(fmt.Sprintf("Функция %s не имеет тела", function.Name()))
+	}
+
+	analyser.Package = function.Pkg
+	analyser.Function = function
+	analyser.run(NewInterpreter(analyser, function))
+
+	return analyser.Results
+}
+
+// run исследует пути, начиная с состояния initial
+func (analyser *Analyser) run(initial Interpreter) {
+	analyser.StartTime = time.Now()
+	if initial.Status == Aborted {
+		analyser.Aborted = append(analyser.Aborted, initial)
+		return
+	}
+	analyser.push(initial)
+
+	for analyser.StatesQueue.Len() > 0 && !analyser.StoppingStrategy.ShouldStop(analyser) {
+		state := heap.Pop(&analyser.StatesQueue).(*Item).value
+		analyser.Steps++
+		analyser.CoveredBlocks[state.frame().Block] = true
+
+		for _, next := range state.interpretDynamically(state.currentInstruction()) {
+			switch next.Status {
+			case Returned:
+				analyser.Results = append(analyser.Results, next)
+			case Panicked:
+				analyser.Panics = append(analyser.Panics, next)
+			case Aborted:
+				analyser.Aborted = append(analyser.Aborted, next)
+			default:
+				analyser.push(next)
+			}
+		}
+	}
+}
+
+// isSatisfiable проверяет выполнимость условия с помощью Z3. В решатель отправляются
+// только независимые части условия, которые ещё не проверялись,
+// а конъюнкты, утверждённые в решателе предыдущими запросами, переиспользуются.
+// Если решатель не смог дать ответ, условие считается выполнимым
+func (analyser *Analyser) isSatisfiable(pathCondition *symbolic.PathConstraints) bool {
+	sat, err := analyser.Solver.Check(pathCondition)
+	if err != nil {
+		return true
+	}
+	return sat
+}
+
+func (analyser *Analyser) push(state Interpreter) {
+	heap.Push(&analyser.StatesQueue, &Item{
+		value:    state,
+		priority: analyser.PathSelector.CalculatePriority(state),
+	})
 }
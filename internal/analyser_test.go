@@ -0,0 +1,1193 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	ssabuilder "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// TestAnalyseBranches тестирует перебор путей простого ветвления
+func TestAnalyseBranches(t *testing.T) {
+	source := `
+package main
+
+func testFunction(x int) int {
+	if x > 0 {
+		return x * 2
+	} else {
+		return x * -1
+	}
+}
+`
+	results := Analyse(source, "testFunction")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 paths, got %d", len(results))
+	}
+
+	returns := map[string]bool{}
+	for _, result := range results {
+		returns[result.frame().ReturnValue.String()] = true
+	}
+	if !returns["(x * 2)"] || !returns["(x * -1)"] {
+		t.Errorf("Unexpected return values: %v", returns)
+	}
+}
+
+// TestAnalyseInfeasiblePath тестирует отсечение невыполнимых путей
+func TestAnalyseInfeasiblePath(t *testing.T) {
+	source := `
+package main
+
+func testInfeasible(x int) int {
+	if x > 0 {
+		if x < 0 {
+			return 1
+		}
+		return 2
+	}
+	return 3
+}
+`
+	results := Analyse(source, "testInfeasible")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 feasible paths, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.frame().ReturnValue.String() == "1" {
+			t.Errorf("Infeasible path was explored: %s", result.PathCondition.String())
+		}
+	}
+}
+
+// TestAnalyseLoopWithPhi тестирует циклы с конкретной границей и φ-функциями
+func TestAnalyseLoopWithPhi(t *testing.T) {
+	source := `
+package main
+
+func testLoop(condition bool) int {
+	result := 0
+	for i := 0; i < 3; i++ {
+		if condition {
+			result += i
+		}
+	}
+	return result
+}
+`
+	results := Analyse(source, "testLoop")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 paths, got %d", len(results))
+	}
+}
+
+// TestStepLimitStrategy тестирует остановку бесконечного перебора по числу шагов
+func TestStepLimitStrategy(t *testing.T) {
+	source := `
+package main
+
+func testWhileLoop(n int) int {
+	i := 0
+	sum := 0
+	for i < n {
+		sum += i
+		i++
+	}
+	return sum
+}
+`
+	results := AnalyseWith(source, "testWhileLoop", &BfsPathSelector{}, &StepLimitStrategy{Limit: 200})
+	if len(results) == 0 {
+		t.Errorf("Expected some paths to be completed within step limit")
+	}
+}
+
+// TestCoverageStrategy тестирует остановку после покрытия всех блоков
+func TestCoverageStrategy(t *testing.T) {
+	source := `
+package main
+
+func testWhileLoop(n int) int {
+	i := 0
+	for i < n {
+		i++
+	}
+	return i
+}
+`
+	strategy := &AnyOfStrategy{Strategies: []StoppingStrategy{
+		&CoverageStrategy{},
+		&StepLimitStrategy{Limit: DefaultStepLimit},
+	}}
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testWhileLoop")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	analyser := NewAnalyser(&BfsPathSelector{}, strategy)
+	results := analyser.AnalyseFunction(function)
+
+	if len(results) == 0 {
+		t.Fatalf("Expected at least one completed path")
+	}
+	if analyser.Steps >= DefaultStepLimit {
+		t.Errorf("Expected coverage strategy to stop the analysis early, got %d steps", analyser.Steps)
+	}
+}
+
+// TestAnalyseIntegerOverflow тестирует поиск переполнения целых фиксированной ширины
+func TestAnalyseIntegerOverflow(t *testing.T) {
+	source := `
+package main
+
+func testOverflow(x int8) int {
+	if x+1 < x {
+		return 1
+	}
+	return 0
+}
+`
+	results := Analyse(source, "testOverflow")
+	if len(results) != 2 {
+		t.Fatalf("Expected overflow and regular paths, got %d", len(results))
+	}
+}
+
+// TestAnalyseBitwise тестирует интерпретацию побитовых операций
+func TestAnalyseBitwise(t *testing.T) {
+	source := `
+package main
+
+func testBitwise(a, b int) int {
+	and := a & b
+	or := a | b
+	xor := a ^ b
+
+	if (and | or) > 0 {
+		return xor << 1
+	}
+	return xor >> 1
+}
+
+func testUnary(x int) int {
+	y := -x
+	z := ^y
+
+	if z > 0 {
+		return z
+	}
+	return y
+}
+`
+	for _, name := range []string{"testBitwise", "testUnary"} {
+		results := Analyse(source, name)
+		if len(results) != 2 {
+			t.Errorf("%s: expected 2 paths, got %d", name, len(results))
+		}
+	}
+}
+
+// TestAnalyseFloat тестирует интерпретацию операций с плавающей точкой
+func TestAnalyseFloat(t *testing.T) {
+	source := `
+package main
+
+func testSimpleSum(a, b float64) float64 {
+	c := a + 1.1
+	if b+c > 10.1 && b+c < 11.125 {
+		return 1.1
+	} else {
+		return 1.2
+	}
+}
+`
+	results := Analyse(source, "testSimpleSum")
+	if len(results) != 3 {
+		t.Errorf("Expected 3 paths, got %d", len(results))
+	}
+}
+
+// TestAnalyseAliasing тестирует ветвление по совпадению входных ссылок
+func TestAnalyseAliasing(t *testing.T) {
+	source := `
+package main
+
+type Foo struct {
+	a int
+	b bool
+}
+
+func testAliasing(foo1 *Foo, foo2 *Foo) int {
+	foo2.a = 5
+	foo1.a = 2
+	if foo2.a == 2 {
+		return 4
+	}
+	return 5
+}
+
+func testStructCopy(foo Foo) int {
+	copied := foo
+	copied.a = 1
+	if foo.a == 1 {
+		return copied.a
+	}
+	return 0
+}
+`
+	results := Analyse(source, "testAliasing")
+	if len(results) != 2 {
+		t.Fatalf("Expected aliased and non-aliased paths, got %d", len(results))
+	}
+
+	results = Analyse(source, "testStructCopy")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 paths for struct copy, got %d", len(results))
+	}
+}
+
+// TestAnalysePointerComparison тестирует сравнение адресов полей и входных ссылок
+func TestAnalysePointerComparison(t *testing.T) {
+	source := `
+package main
+
+type Foo struct {
+	a int
+	b int
+}
+
+func testFieldAddresses(foo *Foo) int {
+	if &foo.a == &foo.b {
+		return 1
+	}
+	return 2
+}
+
+func testLocalAddress(foo *Foo) int {
+	local := &Foo{}
+	if foo == local {
+		return 1
+	}
+	return 2
+}
+`
+	for _, function := range []string{"testFieldAddresses", "testLocalAddress"} {
+		for _, result := range Analyse(source, function) {
+			if value := result.frame().ReturnValue.String(); value != "2" {
+				t.Errorf("%s: distinct addresses compared equal: %s", function, result.PathCondition.String())
+			}
+		}
+	}
+}
+
+// TestAnalyseHeapForking тестирует независимость кучи в разных ветках
+func TestAnalyseHeapForking(t *testing.T) {
+	source := `
+package main
+
+type Counter struct {
+	value int
+}
+
+func testHeapForking(counter *Counter, flag bool) int {
+	if flag {
+		counter.value = 1
+	} else {
+		counter.value = 2
+	}
+	if counter.value == 1 && !flag {
+		return 0
+	}
+	return counter.value
+}
+`
+	results := AnalyseWith(source, "testHeapForking", &BfsPathSelector{}, DefaultStoppingStrategy())
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 paths, got %d", len(results))
+	}
+
+	returns := map[string]bool{}
+	for _, result := range results {
+		returns[result.frame().ReturnValue.String()] = true
+	}
+	if !returns["1"] || !returns["2"] {
+		t.Errorf("Heap writes leaked between branches: %v", returns)
+	}
+}
+
+// TestGenerateTestFile тестирует генерацию теста и его прохождение на исходной функции
+func TestGenerateTestFile(t *testing.T) {
+	source := `
+package main
+
+type Foo struct {
+	a int
+	b bool
+}
+
+func testGenerated(x int8, y float64, foo1 *Foo, foo2 *Foo) int8 {
+	foo2.a = 5
+	foo1.a = 2
+	if foo2.a == 2 && foo1.b {
+		return x
+	}
+	if y > 1.5 {
+		return x + 1
+	}
+	return -x
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testGenerated")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	analyser.AnalyseFunction(function)
+
+	cases := analyser.GenerateTestCases()
+	if len(cases) != len(analyser.Results) || len(cases) != 5 {
+		t.Fatalf("Expected a test case for each of 5 paths, got %d", len(cases))
+	}
+
+	generated, err := GenerateTestFile(function, cases)
+	if err != nil {
+		t.Fatalf("Generated test is not valid Go: %v", err)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil || testing.Short() {
+		t.Skip("Skipping run of the generated test")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module generated\n",
+		"main.go":      source + "\nfunc main() {}\n",
+		"main_test.go": string(generated),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	command := exec.Command("go", "test", "./...")
+	command.Dir = dir
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Errorf("Generated test failed: %v\n%s\n%s", err, output, generated)
+	}
+}
+
+// TestAnalyseCalls тестирует межпроцедурный анализ статических вызовов
+func TestAnalyseCalls(t *testing.T) {
+	source := `
+package main
+
+type Person struct {
+	Age int
+	ID  int
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+func grow(p *Person, years int) {
+	if p.Age < 100 {
+		p.Age = p.Age + years
+	}
+}
+
+func testCalls(x int, p *Person) int {
+	y := abs(x)
+	q, r := divmod(y, 3)
+	grow(p, q)
+	println(r)
+	if p.Age > 200 {
+		return 1
+	}
+	return 0
+}
+`
+	results := Analyse(source, "testCalls")
+	// abs: 2 пути, grow: 2 пути, и в каждом из них сравнение с 200 выполнимо в обе стороны
+	if len(results) != 8 {
+		t.Fatalf("Expected 8 paths, got %d", len(results))
+	}
+	for _, result := range results {
+		if len(result.CallStack) != 1 {
+			t.Errorf("Expected callee frames to be popped, got %d frames", len(result.CallStack))
+		}
+	}
+}
+
+// TestAnalyseRecursionDepth тестирует ограничение глубины рекурсии
+func TestAnalyseRecursionDepth(t *testing.T) {
+	source := `
+package main
+
+func sum(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return n + sum(n-1)
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "sum")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	analyser.MaxCallDepth = 4
+	results := analyser.AnalyseFunction(function)
+
+	// Пути с n <= 0, n == 1, n == 2 и n == 3 укладываются в 4 фрейма
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 paths within call depth, got %d", len(results))
+	}
+	if analyser.StatesQueue.Len() != 0 {
+		t.Errorf("Expected analysis to finish by cutting deep recursion")
+	}
+	if len(analyser.Aborted) != 1 {
+		t.Errorf("Expected the path with deeper recursion to be aborted, got %d", len(analyser.Aborted))
+	}
+}
+
+// TestAnalyseAbortedPaths тестирует, что пути с неподдерживаемыми инструкциями
+// не отбрасываются молча и не дают неполных сводок
+func TestAnalyseAbortedPaths(t *testing.T) {
+	source := `
+package main
+
+func lookup(x int) int {
+	if x > 0 {
+		m := map[int]int{}
+		return m[x]
+	}
+	return x
+}
+
+func testAborted(x int) int {
+	return lookup(x) + 1
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testAborted")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	analyser.DefaultCallMode = SummaryCall
+	results := analyser.AnalyseFunction(function)
+
+	if len(results) != 1 || len(analyser.Aborted) != 1 {
+		t.Fatalf("Expected 1 returned and 1 aborted path, got %d and %d", len(results), len(analyser.Aborted))
+	}
+	if aborted := analyser.Aborted[0]; aborted.Status != Aborted || !strings.Contains(aborted.AbortReason, "MakeMap") {
+		t.Errorf("Expected path aborted at MakeMap, got %s: %s", aborted.Status, aborted.AbortReason)
+	}
+	if summary, exists := analyser.Summaries.Get(function.Pkg.Func("lookup")); !exists || summary != nil {
+		t.Errorf("Expected no summary for partly explored lookup, got %v", summary)
+	}
+}
+
+// TestAnalyseUnsupportedValues тестирует, что неподдерживаемое значение прерывает
+// только свой путь, а не весь анализ
+func TestAnalyseUnsupportedValues(t *testing.T) {
+	source := `
+package main
+
+func greet(x int, name string) string {
+	if x > 0 {
+		return name
+	}
+	return "Hello, " + name
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "greet")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	results := analyser.AnalyseFunction(function)
+
+	if len(results) != 1 || len(analyser.Aborted) != 1 {
+		t.Fatalf("Expected 1 returned and 1 aborted path, got %d and %d", len(results), len(analyser.Aborted))
+	}
+	if aborted := analyser.Aborted[0]; aborted.Status != Aborted || !strings.Contains(aborted.AbortReason, `"Hello, "`) {
+		t.Errorf("Expected path aborted at the string constant, got %s: %s", aborted.Status, aborted.AbortReason)
+	}
+}
+
+// TestAnalyseSummaryRuntimeErrors тестирует, что ошибки вызываемой функции
+// переносятся в сводку и находятся при её подстановке
+func TestAnalyseSummaryRuntimeErrors(t *testing.T) {
+	source := `
+package main
+
+func divide(a, b int) int {
+	return a / b
+}
+
+func testDivide(x int) int {
+	return divide(10, x) + 1
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testDivide")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	analyser.DefaultCallMode = SummaryCall
+	analyser.AnalyseFunction(function)
+
+	if len(analyser.Errors) != 1 || analyser.Errors[0].Kind != DivisionByZero {
+		t.Fatalf("Expected division by zero in callee, got %v", analyser.Errors)
+	}
+	if arguments := analyser.Errors[0].TestCase.Arguments; arguments[0] != "0" {
+		t.Errorf("Expected x == 0, got %v", arguments)
+	}
+	summary, exists := analyser.Summaries.Get(function.Pkg.Func("divide"))
+	if !exists || summary == nil || len(summary.Paths) != 2 {
+		t.Fatalf("Expected summary of divide with error and return paths, got %v", summary)
+	}
+	if failure := analyser.Errors[0]; failure.Position.Line != 5 || !strings.Contains(failure.Instruction, "/") {
+		t.Errorf("Expected error at the division in divide, got %s", failure)
+	}
+}
+
+// TestAnalyseNestedStructPointer тестирует вызовы из homework3 со строковым полем
+// при встраивании и при подстановке сводок
+func TestAnalyseNestedStructPointer(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("..", "homework3", "examples", "test_functions.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(string(source), "testNestedStructPointer")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+	var created *ssa.Call
+	for _, instr := range function.Blocks[0].Instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Call.StaticCallee().Name() == "testStructPointer" {
+			created = call
+		}
+	}
+
+	for _, mode := range []CallMode{InlineCall, SummaryCall} {
+		analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+		analyser.DefaultCallMode = mode
+		results := analyser.AnalyseFunction(function)
+		if len(results) != 1 || len(analyser.Aborted) != 0 {
+			t.Fatalf("Mode %d: expected 1 returned path, got %d and %d aborted", mode, len(results), len(analyser.Aborted))
+		}
+
+		if summary, _ := analyser.Summaries.Get(function.Pkg.Func("testStructPointerModification")); mode == SummaryCall && summary == nil {
+			t.Errorf("Expected a summary of testStructPointerModification")
+		}
+
+		state := results[0]
+		person := state.CallStack[0].LocalMemory[created.Name()].(*pointer)
+		model, err := analyser.Solver.Solve(state.PathCondition)
+		if err != nil || model == nil {
+			t.Fatalf("Mode %d: expected satisfiable path, got %v", mode, err)
+		}
+		name, _, _ := model.Eval(state.Heap.GetFieldValue(person.Base, 0)).(z3.Int).AsInt64()
+		age, _, _ := model.Eval(state.Heap.GetFieldValue(person.Base, 1)).(z3.BV).AsInt64()
+		id, _, _ := model.Eval(state.Heap.GetFieldValue(person.Base, 2)).(z3.BV).AsInt64()
+		if actual := fmt.Sprintf("%s %d %d", analyser.Strings[name], age, id); actual != "Bob 45 3002" {
+			t.Errorf("Mode %d: expected Person{Bob 45 3002}, got %s", mode, actual)
+		}
+	}
+}
+
+// TestAnalyseCallSummaries тестирует подстановку сводок вызываемых функций
+func TestAnalyseCallSummaries(t *testing.T) {
+	source := `
+package main
+
+type Person struct {
+	Age int
+	ID  int
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func grow(p *Person, years int) {
+	if p.Age < 100 {
+		p.Age = p.Age + years
+	}
+}
+
+func reset(p *Person) {
+	p.ID = 7
+}
+
+func testSummaries(x int, y int, p *Person) int {
+	reset(p)
+	grow(p, abs(x))
+	grow(p, abs(y))
+	if p.ID != 7 {
+		return 2
+	}
+	if p.Age > 200 {
+		return 1
+	}
+	return 0
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testSummaries")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	inlined := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	inlinedResults := inlined.AnalyseFunction(function)
+
+	summarized := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	summarized.DefaultCallMode = SummaryCall
+	summarizedResults := summarized.AnalyseFunction(function)
+
+	if len(summarizedResults) != len(inlinedResults) {
+		t.Fatalf("Expected %d paths with summaries, got %d", len(inlinedResults), len(summarizedResults))
+	}
+	if len(summarized.Errors) != len(inlined.Errors) {
+		t.Errorf("Expected %d errors with summaries, got %d", len(inlined.Errors), len(summarized.Errors))
+	}
+	// У grow есть путь с разыменованием nil
+	for name, paths := range map[string]int{"abs": 2, "grow": 3} {
+		summary, exists := summarized.Summaries.Get(function.Pkg.Func(name))
+		if !exists || summary == nil || len(summary.Paths) != paths {
+			t.Errorf("Expected summary of %s with %d paths, got %v", name, paths, summary)
+		}
+	}
+
+	// Путь с результатом 2 недостижим, только если запись reset применилась к p
+	for _, result := range summarizedResults {
+		if value, ok := result.CallStack[0].ReturnValue.(*symbolic.IntConstant); ok && value.Value == 2 {
+			t.Errorf("Expected summary effect of reset to be applied, got path %s", result.PathCondition)
+		}
+	}
+}
+
+// TestAnalyseSummaryFieldAliasing тестирует, что указатели на разные поля одного объекта
+// при подстановке сводки не считаются совпадающими, а на одно поле — считаются
+func TestAnalyseSummaryFieldAliasing(t *testing.T) {
+	source := `
+package main
+
+type Pair struct {
+	A int
+	B int
+}
+
+func assign(p *int, q *int) {
+	*p = 1
+	*q = 2
+}
+
+func fields() int {
+	s := &Pair{}
+	assign(&s.A, &s.B)
+	if s.A != 1 || s.B != 2 {
+		return 1
+	}
+	assign(&s.B, &s.B)
+	if s.A != 1 || s.B != 2 {
+		return 2
+	}
+	return 0
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "fields")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+
+	for _, mode := range []CallMode{InlineCall, SummaryCall} {
+		analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+		analyser.DefaultCallMode = mode
+		results := analyser.AnalyseFunction(function)
+
+		if len(results) == 0 || len(analyser.Errors) != 0 {
+			t.Fatalf("Mode %d: expected error-free paths, got %d and %d errors", mode, len(results), len(analyser.Errors))
+		}
+		for _, result := range results {
+			if value, ok := result.CallStack[0].ReturnValue.(*symbolic.BitVecConstant); !ok || value.Value != 0 {
+				t.Errorf("Mode %d: expected fields to be written independently, got %s on path %s",
+					mode, result.CallStack[0].ReturnValue, result.PathCondition)
+			}
+		}
+	}
+}
+
+// TestAnalyseRuntimeErrors тестирует поиск входных данных, вызывающих панику среды исполнения
+func TestAnalyseRuntimeErrors(t *testing.T) {
+	source := `
+package main
+
+type Person struct {
+	Age int
+}
+
+func divide(a, b int) int {
+	return a / b
+}
+
+func element(s []int, i int) int {
+	return s[i]
+}
+
+func fixed(i int) int {
+	var a [3]int
+	a[1] = 5
+	return a[i]
+}
+
+func age(p *Person) int {
+	return p.Age
+}
+
+func shift(x int, n int8) int {
+	return x << n
+}
+
+func local(x int) int {
+	var p *Person
+	if x > 0 {
+		p = &Person{Age: x}
+	}
+	return p.Age
+}
+`
+	tests := []struct {
+		function string
+		kind     ErrorKind
+		check    func(arguments []string) bool
+	}{
+		{"divide", DivisionByZero, func(arguments []string) bool { return arguments[1] == "0" }},
+		{"element", IndexOutOfRange, func(arguments []string) bool { return arguments[0] != "nil" }},
+		{"fixed", IndexOutOfRange, func(arguments []string) bool { return arguments[0] != "1" }},
+		{"age", NilDereference, func(arguments []string) bool { return arguments[0] == "nil" }},
+		{"shift", NegativeShift, func(arguments []string) bool { return strings.HasPrefix(arguments[1], "-") }},
+		{"local", NilDereference, func(arguments []string) bool { return arguments[0] == "0" || strings.HasPrefix(arguments[0], "-") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, tt.function)
+			if err != nil {
+				t.Fatalf("SSA build failed: %v", err)
+			}
+
+			analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+			results := analyser.AnalyseFunction(function)
+
+			if len(results) != 1 {
+				t.Errorf("Expected error-free path to be explored, got %d paths", len(results))
+			}
+			if len(analyser.Errors) != 1 {
+				t.Fatalf("Expected 1 error, got %d", len(analyser.Errors))
+			}
+			runtimeError := analyser.Errors[0]
+			if runtimeError.Kind != tt.kind {
+				t.Errorf("Expected %s, got %s", tt.kind, runtimeError.Kind)
+			}
+			if runtimeError.Position.Line == 0 {
+				t.Errorf("Expected error position, got %s", runtimeError.Position)
+			}
+			if !tt.check(runtimeError.TestCase.Arguments) {
+				t.Errorf("Unexpected error inputs %v", runtimeError.TestCase.Arguments)
+			}
+		})
+	}
+}
+
+// TestAnalysePanics тестирует разделение путей с возвратом и путей с паникой
+// и функции-допущения
+func TestAnalysePanics(t *testing.T) {
+	source := `
+package main
+
+func assume(condition bool) {}
+
+func checked(n int) int {
+	if n > 10 {
+		panic("Assumption violated: n should be less than or equal to 10")
+	}
+	return n
+}
+
+func assumed(n int) int {
+	assume(n <= 10)
+	return checked(n)
+}
+`
+	build := func(name string) *ssa.Function {
+		function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, name)
+		if err != nil {
+			t.Fatalf("SSA build failed: %v", err)
+		}
+		return function
+	}
+
+	checked := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	checked.AnalyseFunction(build("checked"))
+	if len(checked.Results) != 1 || len(checked.Panics) != 1 {
+		t.Fatalf("Expected 1 returning and 1 panicking path, got %d and %d", len(checked.Results), len(checked.Panics))
+	}
+	if value := checked.Panics[0].PanicValue; value != "Assumption violated: n should be less than or equal to 10" {
+		t.Errorf("Unexpected panic value %q", value)
+	}
+
+	for _, mode := range []CallMode{InlineCall, SummaryCall} {
+		function := build("assumed")
+
+		plain := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+		plain.DefaultCallMode = mode
+		plain.AnalyseFunction(function)
+		if len(plain.Panics) != 1 {
+			t.Errorf("Expected panic in callee to be reachable without assumption, got %d panics", len(plain.Panics))
+		}
+
+		assumed := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+		assumed.DefaultCallMode = mode
+		assumed.AssumeFunctions[function.Pkg.Func("assume")] = true
+		assumed.AnalyseFunction(function)
+		if len(assumed.Results) != 1 || len(assumed.Panics) != 0 {
+			t.Errorf("Expected assumption to rule out panic, got %d results and %d panics", len(assumed.Results), len(assumed.Panics))
+		}
+	}
+}
+
+// TestSolverSession тестирует переиспользование общего префикса конъюнктов между запросами
+func TestSolverSession(t *testing.T) {
+	session := NewSolverSession(translator.NewZ3Translator())
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	greater := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.GT)
+	less := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(10), symbolic.LT)
+	large := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(20), symbolic.GT)
+	small := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(5), symbolic.LT)
+
+	for _, query := range []struct {
+		conjuncts []symbolic.SymbolicExpression
+		sat       bool
+	}{
+		{[]symbolic.SymbolicExpression{greater, less}, true},
+		{[]symbolic.SymbolicExpression{greater, less, large}, false},
+		{[]symbolic.SymbolicExpression{greater, less, small}, true},
+		{[]symbolic.SymbolicExpression{large}, true},
+	} {
+		sat, err := session.Check(query.conjuncts)
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if sat != query.sat {
+			t.Errorf("Expected %v for %v, got %v", query.sat, query.conjuncts, sat)
+		}
+	}
+
+	expected := SessionStats{Checks: 4, Asserted: 5, Reused: 4, Popped: 4}
+	if stats := session.Stats(); stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+// TestAnalyseIncrementalSolving тестирует, что при обходе в глубину префиксы условий путей не утверждаются повторно
+func TestAnalyseIncrementalSolving(t *testing.T) {
+	source := `
+package main
+
+func testFunction(a, b, c int) int {
+	result := 0
+	if a > 0 {
+		result++
+	}
+	if b > a {
+		result++
+	}
+	if c > b {
+		result++
+	}
+	return result
+}
+`
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "testFunction")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	if results := analyser.AnalyseFunction(function); len(results) != 8 {
+		t.Fatalf("Expected 8 paths, got %d", len(results))
+	}
+
+	stats := analyser.Solver.SessionStats()
+	if stats.Reused == 0 || stats.Asserted >= stats.Checks*2 {
+		t.Errorf("Expected common prefixes to be reused, got %+v", stats)
+	}
+}
+
+// TestIndependentSolver тестирует проверку независимых частей условия пути и объединение их моделей
+func TestIndependentSolver(t *testing.T) {
+	z3Translator := translator.NewZ3Translator()
+	solver := NewIndependentSolver(z3Translator, NewCounterexampleCache(z3Translator))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	z := symbolic.NewSymbolicVariable("z", symbolic.IntType)
+
+	var parent *symbolic.PathConstraints
+	parent = parent.Add(symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(10), symbolic.GT))
+	parent = parent.Add(symbolic.NewBinaryOperation(y, symbolic.NewIntConstant(0), symbolic.LT))
+	if sat, err := solver.Check(parent); err != nil || !sat {
+		t.Fatalf("Expected %s to be satisfiable, got %v, %v", parent, sat, err)
+	}
+
+	// Новое условие связано только с y, часть с x берётся из кэша
+	child := parent.Add(symbolic.NewBinaryOperation(y, z, symbolic.EQ))
+	model, err := solver.Solve(child)
+	if err != nil || model == nil {
+		t.Fatalf("Expected %s to be satisfiable, got %v", child, err)
+	}
+	expected := IndependenceStats{Queries: 2, Partitions: 4, Solved: 3, Cached: 1, Sliced: 3}
+	if stats := solver.Stats(); stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	values := make(map[string]string)
+	for _, variable := range []*symbolic.SymbolicVariable{x, y, z} {
+		values[variable.Name] = model.Eval(variable).String()
+	}
+	if values["y"] != values["z"] || values["y"] == values["x"] {
+		t.Errorf("Expected merged model with x > 10 and y = z < 0, got %v", values)
+	}
+
+	unsat := child.Add(symbolic.NewBinaryOperation(z, symbolic.NewIntConstant(0), symbolic.GT))
+	if sat, err := solver.Check(unsat); err != nil || sat {
+		t.Errorf("Expected %s to be unsatisfiable, got %v, %v", unsat, sat, err)
+	}
+}
+
+// TestModelEvalOwnership тестирует, что переменная вычисляется в модели части, которая её упоминает,
+// даже если модель другой части тоже задаёт эту переменную
+func TestModelEvalOwnership(t *testing.T) {
+	z3Translator := translator.NewZ3Translator()
+	solver := NewIndependentSolver(z3Translator, NewCounterexampleCache(z3Translator))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	z := symbolic.NewSymbolicVariable("z", symbolic.IntType)
+	greater := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(5), symbolic.GT)
+	negative := symbolic.NewBinaryOperation(y, symbolic.NewIntConstant(0), symbolic.LT)
+
+	modelOf := func(constraints ...symbolic.SymbolicExpression) *z3.Model {
+		session := NewSolverSession(z3Translator)
+		if sat, err := session.Check(constraints); err != nil || !sat {
+			t.Fatalf("Expected %v to be satisfiable, got %v", constraints, err)
+		}
+		return session.Model()
+	}
+
+	var pathCondition *symbolic.PathConstraints
+	pathCondition = pathCondition.Add(greater).Add(negative)
+	model, err := solver.Solve(pathCondition)
+	if err != nil || model == nil {
+		t.Fatalf("Expected %s to be satisfiable, got %v", pathCondition, err)
+	}
+
+	// Модель части x > 5 задаёт и y = 0, как модель взятого из кэша запроса
+	spurious := symbolic.NewBinaryOperation(y, symbolic.NewIntConstant(0), symbolic.EQ)
+	model.parts[0].Model = modelOf(symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(6), symbolic.EQ), spurious)
+	model.parts[1].Model = modelOf(symbolic.NewBinaryOperation(y, symbolic.NewIntConstant(-3), symbolic.EQ))
+
+	for expr, expected := range map[symbolic.SymbolicExpression]int64{
+		y: -3,
+		z: 0,
+		symbolic.NewBinaryOperation(x, y, symbolic.ADD): 3,
+	} {
+		actual, ok := model.Eval(expr).(z3.Int)
+		if value, literal, _ := actual.AsInt64(); !ok || !literal || value != expected {
+			t.Errorf("Expected %s = %d, got %v", expr, expected, model.Eval(expr))
+		}
+	}
+}
+
+// TestIndependentSolverSessions тестирует, что чередующиеся части решаются в своих сессиях
+// и не вытесняют префиксы друг друга
+func TestIndependentSolverSessions(t *testing.T) {
+	z3Translator := translator.NewZ3Translator()
+	solver := NewIndependentSolver(z3Translator, NewCounterexampleCache(z3Translator))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	zero := symbolic.NewIntConstant(0)
+	large := symbolic.NewIntConstant(1000)
+
+	var parent *symbolic.PathConstraints
+	parent = parent.Add(symbolic.NewBinaryOperation(x, zero, symbolic.GT))
+	parent = parent.Add(symbolic.NewBinaryOperation(y, zero, symbolic.GT))
+	for _, pathCondition := range []*symbolic.PathConstraints{
+		parent,
+		parent.Add(symbolic.NewBinaryOperation(x, large, symbolic.GT)),
+		parent.Add(symbolic.NewBinaryOperation(y, large, symbolic.GT)),
+	} {
+		if sat, err := solver.Check(pathCondition); err != nil || !sat {
+			t.Fatalf("Expected %s to be satisfiable, got %v, %v", pathCondition, sat, err)
+		}
+	}
+
+	expected := SessionStats{Checks: 4, Asserted: 4, Reused: 2, Popped: 0}
+	if stats := solver.SessionStats(); stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
+
+// TestCounterexampleCacheProjection тестирует, что модель, взятая у другого запроса,
+// не переносит в тест значения переменных, которых в запросе нет
+func TestCounterexampleCacheProjection(t *testing.T) {
+	source := `
+package main
+
+func f(x, y int, c bool) int {
+	if x > 5 {
+		if c {
+			if x+y == 8 {
+				return 1
+			}
+			return 2
+		}
+		if x < 100 {
+			if y < 0 {
+				return 3
+			}
+			return 4
+		}
+	}
+	return 0
+}
+`
+	f := func(x, y int, c bool) int {
+		if x > 5 {
+			if c {
+				if x+y == 8 {
+					return 1
+				}
+				return 2
+			}
+			if x < 100 {
+				if y < 0 {
+					return 3
+				}
+				return 4
+			}
+		}
+		return 0
+	}
+
+	function, err := ssabuilder.NewBuilder().ParseAndBuildSSA(source, "f")
+	if err != nil {
+		t.Fatalf("SSA build failed: %v", err)
+	}
+	analyser := NewAnalyser(&DfsPathSelector{}, DefaultStoppingStrategy())
+	analyser.AnalyseFunction(function)
+
+	cases := analyser.GenerateTestCases()
+	if len(cases) != 6 {
+		t.Fatalf("Expected a test case for each of 6 paths, got %d", len(cases))
+	}
+	for _, testCase := range cases {
+		x, errX := strconv.Atoi(testCase.Arguments[0])
+		y, errY := strconv.Atoi(testCase.Arguments[1])
+		c, errC := strconv.ParseBool(testCase.Arguments[2])
+		if errX != nil || errY != nil || errC != nil {
+			t.Fatalf("Unexpected arguments %v", testCase.Arguments)
+		}
+		if actual := strconv.Itoa(f(x, y, c)); actual != testCase.Expected {
+			t.Errorf("f(%d, %d, %v) = %s, want %s", x, y, c, actual, testCase.Expected)
+		}
+	}
+}
+
+// TestCounterexampleCache тестирует вывод результатов запросов из решённых подмножеств и надмножеств
+func TestCounterexampleCache(t *testing.T) {
+	z3Translator := translator.NewZ3Translator()
+	cache := NewCounterexampleCache(z3Translator)
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	positive := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.GT)
+	negative := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.LT)
+	small := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(10), symbolic.LT)
+	large := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(7), symbolic.GT)
+	other := symbolic.NewBinaryOperation(y, symbolic.NewIntConstant(1), symbolic.GT)
+	notNine := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(9), symbolic.NE)
+
+	z3Context := z3Translator.GetContext().(*z3.Context)
+	z3X, _ := z3Translator.TranslateExpression(x)
+	modelOf := func(value int64) *z3.Model {
+		solver := z3.NewSolver(z3Context)
+		solver.Assert(z3X.(z3.Int).Eq(z3Context.FromInt(value, z3X.(z3.Int).Sort()).(z3.Int)))
+		if sat, err := solver.Check(); err != nil || !sat {
+			t.Fatalf("Failed to build model x = %d: %v", value, err)
+		}
+		return solver.Model()
+	}
+
+	for _, query := range []struct {
+		conjuncts []symbolic.SymbolicExpression
+		sat       bool
+		solved    bool
+		model     *z3.Model // модель, которую вернёт решатель
+	}{
+		{[]symbolic.SymbolicExpression{positive}, true, true, modelOf(5)},
+		// Модель x = 5 выполняет и x < 10
+		{[]symbolic.SymbolicExpression{positive, small}, true, false, nil},
+		{[]symbolic.SymbolicExpression{small, positive, small}, true, false, nil},
+		// Модель x = 5 не выполняет x > 7
+		{[]symbolic.SymbolicExpression{positive, large}, true, true, modelOf(8)},
+		// Модель надмножества выполняет запрос без проверок
+		{[]symbolic.SymbolicExpression{large}, true, false, nil},
+		// Модель x = 8 выполняет оба недостающих конъюнкта
+		{[]symbolic.SymbolicExpression{notNine, large, small, positive}, true, false, nil},
+		{[]symbolic.SymbolicExpression{negative, positive}, false, true, nil},
+		{[]symbolic.SymbolicExpression{other, positive, negative}, false, false, nil},
+	} {
+		solved := false
+		sat, _, err := cache.Check(query.conjuncts, func() (bool, *z3.Model, error) {
+			solved = true
+			return query.sat, query.model, nil
+		})
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if sat != query.sat || solved != query.solved {
+			t.Errorf("Expected sat %v and solved %v for %v, got %v and %v", query.sat, query.solved, query.conjuncts, sat, solved)
+		}
+	}
+
+	stats := cache.Stats()
+	expected := QueryCacheStats{Hits: 5, Exact: 1, Subset: 1, Model: 3, Misses: 3}
+	stats.Saved = 0
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+}
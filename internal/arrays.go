@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// indexType — сорт индексов символьных массивов, соответствующий int в Go
+const indexType = symbolic.Int64Type
+
+// sliceValue представляет срез: содержимое хранится массивом в поле 0 объекта Base,
+// поэтому копии среза видят записи друг друга. Поддерживаются элементы базовых типов
+type sliceValue struct {
+	Base   *symbolic.Ref
+	Length symbolic.SymbolicExpression
+
+	// Input — содержимое входного среза до записей в него
+	Input symbolic.SymbolicExpression
+}
+
+func (s *sliceValue) Type() symbolic.ExpressionType {
+	return symbolic.ArrayType
+}
+
+func (s *sliceValue) String() string {
+	return fmt.Sprintf("%s[:%s]", s.Base.String(), s.Length.String())
+}
+
+// Accept обходит базовую ссылку, как и для указателя
+func (s *sliceValue) Accept(visitor symbolic.Visitor) interface{} {
+	return s.Base.Accept(visitor)
+}
+
+func (s *sliceValue) Equals(other symbolic.SymbolicExpression) bool {
+	o, ok := other.(*sliceValue)
+	return ok && symbolic.Equal(s.Base, o.Base) && symbolic.Equal(s.Length, o.Length) && symbolic.Equal(s.Input, o.Input)
+}
+
+func (s *sliceValue) Hash() uint64 {
+	return symbolic.HashCombine(symbolic.HashString("slice"), s.Base.Hash(), s.Length.Hash(), symbolic.HashOf(s.Input))
+}
+
+// inputArray возвращает символьный массив входного параметра name
+func inputArray(name string, elem types.Type) *symbolic.SymbolicVariable {
+	return symbolic.NewArrayVariable(name, indexType, elementType(elem))
+}
+
+// newSliceParameter создаёт входной срез с символьными длиной и содержимым
+func (interpreter *Interpreter) newSliceParameter(name string, tpe *types.Slice) symbolic.SymbolicExpression {
+	input := inputArray(name, tpe.Elem())
+	base := interpreter.Heap.AllocateStruct(1)
+	interpreter.Heap.AssignField(base, 0, input)
+
+	length := symbolic.NewSymbolicVariable("len("+name+")", indexType)
+	nonNegative := symbolic.NewBinaryOperation(length, symbolic.NewBitVecConstant(0, indexType), symbolic.GE)
+	interpreter.PathCondition = conjunction(interpreter.PathCondition, nonNegative)
+
+	return &sliceValue{Base: base, Length: length, Input: input}
+}
+
+// elementType возвращает тип символьного выражения элемента массива
+func elementType(elem types.Type) symbolic.ExpressionType {
+	if _, ok := elem.Underlying().(*types.Basic); !ok {
+		panic(unsupported(fmt.Sprintf("неподдерживаемый тип элемента массива %s", elem.String())))
+	}
+	return symbolicType(elem)
+}
+
+// zeroValue возвращает нулевое значение типа tpe. Массив заполняется нулями элемента,
+// а нулевой указатель ссылается на нулевой объект
+func zeroValue(tpe types.Type) symbolic.SymbolicExpression {
+	switch t := tpe.Underlying().(type) {
+	case *types.Array:
+		return symbolic.NewArrayConstant(indexType, zeroValue(t.Elem()))
+	case *types.Pointer:
+		return &pointer{Base: symbolic.NilRef()}
+	}
+	return symbolic.ZeroValue(symbolicType(tpe))
+}
+
+// interpretIndexAddr вычисляет адрес элемента массива или среза
+func (interpreter *Interpreter) interpretIndexAddr(instr *ssa.IndexAddr) symbolic.SymbolicExpression {
+	index := interpreter.resolveIndex(instr.Index)
+
+	switch instr.X.Type().Underlying().(type) {
+	case *types.Slice:
+		slice := interpreter.resolveExpression(instr.X).(*sliceValue)
+		return &pointer{Base: slice.Base, Index: index}
+	case *types.Pointer:
+		address := interpreter.resolvePointer(instr.X)
+		if address.Index != nil {
+			panic(unsupported(fmt.Sprintf("неподдерживаемый вложенный массив %s", instr.String())))
+		}
+		return &pointer{Base: address.Base, Field: address.Field, Index: index}
+	default:
+		panic(unsupported(fmt.Sprintf("неподдерживаемое взятие адреса элемента %s", instr.String())))
+	}
+}
+
+// interpretIndex читает элемент массива, переданного по значению
+func (interpreter *Interpreter) interpretIndex(instr *ssa.Index) symbolic.SymbolicExpression {
+	if _, ok := instr.X.Type().Underlying().(*types.Array); !ok {
+		panic(unsupported(fmt.Sprintf("неподдерживаемое чтение элемента %s", instr.String())))
+	}
+	return symbolic.NewArraySelect(interpreter.resolveExpression(instr.X), interpreter.resolveIndex(instr.Index))
+}
+
+// resolveIndex приводит индекс к сорту индексов массивов
+func (interpreter *Interpreter) resolveIndex(value ssa.Value) symbolic.SymbolicExpression {
+	index := interpreter.resolveExpression(value)
+	if index.Type() != indexType {
+		return symbolic.NewConversion(index, indexType)
+	}
+	return index
+}
+
+// length возвращает длину массива или среза
+func (interpreter *Interpreter) length(value ssa.Value) symbolic.SymbolicExpression {
+	switch t := value.Type().Underlying().(type) {
+	case *types.Slice:
+		return interpreter.resolveExpression(value).(*sliceValue).Length
+	case *types.Array:
+		return symbolic.NewBitVecConstant(t.Len(), indexType)
+	case *types.Pointer:
+		if array, ok := t.Elem().Underlying().(*types.Array); ok {
+			return symbolic.NewBitVecConstant(array.Len(), indexType)
+		}
+	}
+	panic(unsupported(fmt.Sprintf("неподдерживаемый аргумент len %s", value.String())))
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"fmt"
+	"go/constant"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// DefaultMaxCallDepth — глубина стека вызовов по умолчанию, после которой путь прерывается
+const DefaultMaxCallDepth = 16
+
+// interpretCall исполняет статический вызов функции, тело которой есть в программе,
+// помещая на стек новый фрейм с привязанными параметрами
+func (interpreter *Interpreter) interpretCall(instr *ssa.Call) []Interpreter {
+	if builtin, ok := instr.Call.Value.(*ssa.Builtin); ok {
+		return interpreter.interpretBuiltin(instr, builtin)
+	}
+
+	callee := instr.Call.StaticCallee()
+	if callee != nil && interpreter.Analyser.AssumeFunctions[callee] {
+		return interpreter.interpretAssume(instr)
+	}
+	if callee == nil || len(callee.Blocks) == 0 || len(callee.FreeVars) > 0 {
+		return interpreter.abort("неподдерживаемый вызов: " + instr.String())
+	}
+
+	if interpreter.Analyser.callMode(callee) == SummaryCall {
+		if summary := interpreter.Analyser.summary(callee); summary != nil {
+			return interpreter.applySummary(instr, summary)
+		}
+	}
+
+	if len(interpreter.CallStack) >= interpreter.Analyser.MaxCallDepth {
+		// Рекурсия глубже ограничения не исследуется
+		return interpreter.abort("превышена глубина стека вызовов: " + instr.String())
+	}
+
+	frame := CallStackFrame{
+		Function:    callee,
+		LocalMemory: make(map[string]symbolic.SymbolicExpression),
+		Block:       callee.Blocks[0],
+	}
+	for i, param := range callee.Params {
+		frame.LocalMemory[param.Name()] = interpreter.resolveExpression(instr.Call.Args[i])
+	}
+	interpreter.CallStack = append(interpreter.CallStack, frame)
+
+	return []Interpreter{*interpreter}
+}
+
+// interpretBuiltin исполняет вызов встроенной функции
+func (interpreter *Interpreter) interpretBuiltin(instr *ssa.Call, builtin *ssa.Builtin) []Interpreter {
+	switch builtin.Name() {
+	case "print", "println":
+		// Вывод не влияет на символьное состояние
+	case "len":
+		interpreter.frame().LocalMemory[instr.Name()] = interpreter.length(instr.Call.Args[0])
+	default:
+		return interpreter.abort("неподдерживаемая встроенная функция: " + instr.String())
+	}
+
+	interpreter.frame().InstrIndex++
+	return []Interpreter{*interpreter}
+}
+
+// interpretReturn завершает текущий фрейм. Результат вызванной функции
+// записывается в регистр инструкции вызова в вызывающем фрейме
+func (interpreter *Interpreter) interpretReturn(instr *ssa.Return) []Interpreter {
+	frame := interpreter.frame()
+	results := make([]symbolic.SymbolicExpression, len(instr.Results))
+	for i, result := range instr.Results {
+		results[i] = interpreter.resolveExpression(result)
+	}
+
+	if len(interpreter.CallStack) == 1 {
+		if len(results) == 1 {
+			frame.ReturnValue = results[0]
+		}
+		interpreter.Status = Returned
+		return []Interpreter{*interpreter}
+	}
+
+	interpreter.CallStack = interpreter.CallStack[:len(interpreter.CallStack)-1]
+	interpreter.assignCallResults(interpreter.currentInstruction().(*ssa.Call), results)
+
+	return []Interpreter{*interpreter}
+}
+
+// assignCallResults записывает результаты вызова в регистр инструкции call
+// и переходит к следующей инструкции вызывающего фрейма
+func (interpreter *Interpreter) assignCallResults(call *ssa.Call, results []symbolic.SymbolicExpression) {
+	caller := interpreter.frame()
+	switch len(results) {
+	case 0:
+	case 1:
+		caller.LocalMemory[call.Name()] = results[0]
+	default:
+		for i, result := range results {
+			caller.LocalMemory[tupleElementName(call.Name(), i)] = result
+		}
+	}
+	caller.InstrIndex++
+}
+
+// interpretExtract читает элемент результата вызова, возвращающего несколько значений
+func (interpreter *Interpreter) interpretExtract(instr *ssa.Extract) symbolic.SymbolicExpression {
+	name := tupleElementName(instr.Tuple.Name(), instr.Index)
+	value, exists := interpreter.frame().LocalMemory[name]
+	if !exists {
+		panic(fmt.Sprintf("Значение %s не определено", name))
+	}
+	return value
+}
+
+// tupleElementName возвращает имя, под которым в локальной памяти хранится элемент кортежа
+func tupleElementName(tuple string, index int) string {
+	return fmt.Sprintf("%s#%d", tuple, index)
+}
+
+// interpretAssume добавляет аргумент вызова функции-допущения к условию пути.
+// Путь, на котором допущение невыполнимо, отбрасывается
+func (interpreter *Interpreter) interpretAssume(instr *ssa.Call) []Interpreter {
+	if len(instr.Call.Args) != 1 {
+		panic(fmt.Sprintf("Допущение должно принимать одно условие: %s", instr.String()))
+	}
+
+	condition := symbolic.Simplify(interpreter.resolveExpression(instr.Call.Args[0]))
+	if value, ok := condition.(*symbolic.BoolConstant); ok {
+		if !value.Value {
+			return nil
+		}
+	} else {
+		pathCondition := conjunction(interpreter.PathCondition, condition)
+		if !interpreter.Analyser.isSatisfiable(pathCondition) {
+			return nil
+		}
+		interpreter.PathCondition = pathCondition
+	}
+
+	interpreter.frame().InstrIndex++
+	return []Interpreter{*interpreter}
+}
+
+// interpretPanic завершает путь паникой, сохраняя описание её значения
+func (interpreter *Interpreter) interpretPanic(instr *ssa.Panic) []Interpreter {
+	interpreter.Status = Panicked
+	interpreter.PanicValue = interpreter.describePanicValue(instr.X)
+	return []Interpreter{*interpreter}
+}
+
+// describePanicValue возвращает строковое представление значения, переданного в panic
+func (interpreter *Interpreter) describePanicValue(value ssa.Value) string {
+	if wrapped, ok := value.(*ssa.MakeInterface); ok {
+		value = wrapped.X
+	}
+
+	switch v := value.(type) {
+	case *ssa.Const:
+		switch {
+		case v.Value == nil:
+			return "nil"
+		case v.Value.Kind() == constant.String:
+			return constant.StringVal(v.Value)
+		default:
+			return v.Value.String()
+		}
+	default:
+		if expr, exists := interpreter.frame().LocalMemory[value.Name()]; exists {
+			return expr.String()
+		}
+		return value.String()
+	}
+}
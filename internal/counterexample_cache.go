@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"time"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// CounterexampleCache хранит результаты запросов к решателю, ключом которых служит
+// множество конъюнктов без учёта порядка и повторов. Кроме точного совпадения,
+// результат выводится из ранее решённых запросов: надмножество невыполнимого
+// множества невыполнимо, а множество, которому удовлетворяет сохранённая модель, выполнимо.
+// Модели берутся у не более чем MaxModelCandidates последних выполнимых запросов
+// с общими конъюнктами — как подмножеств запроса, так и его надмножеств.
+// Модель возвращается ограниченной переменными запроса
+type CounterexampleCache struct {
+	translator *translator.Z3Translator
+
+	entries map[uint64][]*cacheEntry // хэш множества -> запросы с этим хэшем
+	unsat   map[uint64][]*cacheEntry // хэш последнего конъюнкта -> невыполнимые запросы
+	sat     map[uint64][]*cacheEntry // хэш конъюнкта -> последние выполнимые запросы с ним
+	stats   QueryCacheStats
+}
+
+// MaxModelCandidates ограничивает число сохранённых моделей, проверяемых для одного запроса
+const MaxModelCandidates = 8
+
+// cacheEntry — решённый запрос
+type cacheEntry struct {
+	constraints []symbolic.SymbolicExpression // без повторов, в порядке запроса
+	sat         bool
+	model       *ProjectedModel
+	duration    time.Duration // время решения запроса
+}
+
+// ProjectedModel — модель запроса, ограниченная переменными его конъюнктов.
+// Модель решателя, взятая у другого запроса, задаёт и переменные, которых
+// в запросе нет, но их значения к запросу не относятся
+type ProjectedModel struct {
+	Model       *z3.Model
+	constraints []symbolic.SymbolicExpression
+	variables   map[string]bool // собираются при первом обращении
+}
+
+func newProjectedModel(model *z3.Model, constraints []symbolic.SymbolicExpression) *ProjectedModel {
+	if model == nil {
+		return nil
+	}
+	return &ProjectedModel{Model: model, constraints: constraints}
+}
+
+// Value возвращает значение переменной name или nil, если запрос её не упоминает
+func (model *ProjectedModel) Value(name string, variable z3.Value) z3.Value {
+	if model.variables == nil {
+		model.variables = make(map[string]bool)
+		for _, constraint := range model.constraints {
+			for _, variable := range symbolic.Variables(constraint) {
+				model.variables[variable] = true
+			}
+		}
+	}
+	if !model.variables[name] {
+		return nil
+	}
+	return model.Model.Eval(variable, true)
+}
+
+// QueryCacheStats — статистика кэша запросов
+type QueryCacheStats struct {
+	Hits   int // запросы, ответ на которые найден в кэше
+	Exact  int // из них совпавшие с решённым запросом
+	Subset int // из них содержащие невыполнимое подмножество
+	Model  int // из них выполненные моделью подмножества или надмножества
+	Misses int // запросы, отправленные в решатель
+
+	// Saved — время решения запросов, взятых из кэша, за вычетом времени поиска в кэше
+	Saved time.Duration
+}
+
+func NewCounterexampleCache(z3Translator *translator.Z3Translator) *CounterexampleCache {
+	return &CounterexampleCache{
+		translator: z3Translator,
+		entries:    make(map[uint64][]*cacheEntry),
+		unsat:      make(map[uint64][]*cacheEntry),
+		sat:        make(map[uint64][]*cacheEntry),
+	}
+}
+
+// Stats возвращает статистику кэша
+func (cache *CounterexampleCache) Stats() QueryCacheStats {
+	return cache.stats
+}
+
+// Check проверяет выполнимость конъюнкции conjuncts, обращаясь к решению solve,
+// только если ответ нельзя вывести из кэша. Для выполнимой конъюнкции возвращается модель
+func (cache *CounterexampleCache) Check(conjuncts []symbolic.SymbolicExpression, solve func() (bool, *z3.Model, error)) (bool, *ProjectedModel, error) {
+	start := time.Now()
+	query := newCacheQuery(conjuncts)
+	if entry := cache.lookup(query); entry != nil {
+		cache.stats.Hits++
+		cache.stats.Saved += entry.duration - time.Since(start)
+		return entry.sat, entry.model, nil
+	}
+	cache.stats.Misses++
+	cache.stats.Saved -= time.Since(start)
+
+	start = time.Now()
+	sat, model, err := solve()
+	if err != nil {
+		return false, nil, err
+	}
+	entry := &cacheEntry{
+		constraints: query.constraints,
+		sat:         sat,
+		model:       newProjectedModel(model, query.constraints),
+		duration:    time.Since(start),
+	}
+	cache.entries[query.hash] = append(cache.entries[query.hash], entry)
+	if !sat && len(query.constraints) > 0 {
+		// Последний конъюнкт обычно добавлен последним ветвлением и входит в немногие запросы
+		last := query.constraints[len(query.constraints)-1].Hash()
+		cache.unsat[last] = append(cache.unsat[last], entry)
+	}
+	cache.indexModel(entry)
+	return sat, entry.model, nil
+}
+
+// indexModel запоминает модель выполнимого запроса entry среди последних
+// MaxModelCandidates моделей каждого его конъюнкта
+func (cache *CounterexampleCache) indexModel(entry *cacheEntry) {
+	if !entry.sat || entry.model == nil {
+		return
+	}
+	for _, constraint := range entry.constraints {
+		hash := constraint.Hash()
+		recent := append(cache.sat[hash], entry)
+		if len(recent) > MaxModelCandidates {
+			recent = recent[1:]
+		}
+		cache.sat[hash] = recent
+	}
+}
+
+// lookup ищет запрос, из результата которого следует результат для query
+func (cache *CounterexampleCache) lookup(query cacheQuery) *cacheEntry {
+	for _, entry := range cache.entries[query.hash] {
+		if query.includes(entry, len(query.constraints)) {
+			cache.stats.Exact++
+			return entry
+		}
+	}
+
+	// Невыполнимое подмножество содержит свой последний конъюнкт, поэтому ищется по конъюнктам запроса
+	for _, constraint := range query.constraints {
+		for _, entry := range cache.unsat[constraint.Hash()] {
+			if query.includes(entry, len(entry.constraints)) {
+				cache.stats.Subset++
+				return cache.derive(query, entry)
+			}
+		}
+	}
+
+	// Модель выполнимого запроса выполняет все его конъюнкты, поэтому в ней проверяются
+	// только недостающие конъюнкты query; у надмножества query их нет. Кандидаты
+	// перебираются от последнего конъюнкта query, добавленного последним ветвлением,
+	// а среди запросов с одним конъюнктом — от недавних к давним
+	candidates := make(map[*cacheEntry]bool)
+	for i := len(query.constraints) - 1; i >= 0 && len(candidates) < MaxModelCandidates; i-- {
+		recent := cache.sat[query.constraints[i].Hash()]
+		for j := len(recent) - 1; j >= 0 && len(candidates) < MaxModelCandidates; j-- {
+			entry := recent[j]
+			if candidates[entry] {
+				continue
+			}
+			candidates[entry] = true
+			if cache.satisfiesMissing(entry, query) {
+				cache.stats.Model++
+				return cache.derive(query, entry)
+			}
+		}
+	}
+	return nil
+}
+
+// satisfiesMissing сообщает, выполняет ли модель entry конъюнкты query, которых нет в entry
+func (cache *CounterexampleCache) satisfiesMissing(entry *cacheEntry, query cacheQuery) bool {
+	solved := make([]bool, len(query.constraints))
+	for _, constraint := range entry.constraints {
+		if position := query.position(constraint); position >= 0 {
+			solved[position] = true
+		}
+	}
+	for i, constraint := range query.constraints {
+		if !solved[i] && !cache.satisfies(entry.model.Model, constraint) {
+			return false
+		}
+	}
+	return true
+}
+
+// derive запоминает результат source для query, чтобы повторный запрос совпал точно.
+// Модель source выполняет все конъюнкты query, но ограничивается переменными query
+func (cache *CounterexampleCache) derive(query cacheQuery, source *cacheEntry) *cacheEntry {
+	entry := &cacheEntry{constraints: query.constraints, sat: source.sat, duration: source.duration}
+	if source.model != nil {
+		entry.model = newProjectedModel(source.model.Model, query.constraints)
+	}
+	cache.entries[query.hash] = append(cache.entries[query.hash], entry)
+	cache.indexModel(entry)
+	return entry
+}
+
+// satisfies сообщает, выполняется ли constraint в модели model
+func (cache *CounterexampleCache) satisfies(model *z3.Model, constraint symbolic.SymbolicExpression) bool {
+	z3Constraint, err := cache.translator.TranslateExpression(constraint)
+	if err != nil {
+		return false
+	}
+	evaluated, ok := model.Eval(z3Constraint.(z3.Value), true).(z3.Bool)
+	if !ok {
+		return false
+	}
+	value, concrete := evaluated.AsBool()
+	return concrete && value
+}
+
+// cacheQuery — множество конъюнктов запроса
+type cacheQuery struct {
+	constraints []symbolic.SymbolicExpression
+	positions   map[uint64][]int // хэш выражения -> номера конъюнктов с этим хэшем
+	hash        uint64           // не зависит от порядка конъюнктов
+}
+
+func newCacheQuery(conjuncts []symbolic.SymbolicExpression) cacheQuery {
+	query := cacheQuery{positions: make(map[uint64][]int, len(conjuncts))}
+	for _, conjunct := range conjuncts {
+		if query.position(conjunct) < 0 {
+			hash := conjunct.Hash()
+			query.positions[hash] = append(query.positions[hash], len(query.constraints))
+			query.constraints = append(query.constraints, conjunct)
+			query.hash += constraintHash(conjunct)
+		}
+	}
+	return query
+}
+
+// position возвращает номер конъюнкта constraint в запросе или -1
+func (query cacheQuery) position(constraint symbolic.SymbolicExpression) int {
+	for _, position := range query.positions[constraint.Hash()] {
+		if symbolic.Equal(query.constraints[position], constraint) {
+			return position
+		}
+	}
+	return -1
+}
+
+// includes сообщает, что запрос entry состоит из size конъюнктов и все они входят в query
+func (query cacheQuery) includes(entry *cacheEntry, size int) bool {
+	if len(entry.constraints) != size {
+		return false
+	}
+	for _, constraint := range entry.constraints {
+		if query.position(constraint) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// constraintHash — вклад конъюнкта в хэш множества. Хэш множества равен сумме вкладов,
+// поэтому хэш множества без одного конъюнкта получается вычитанием
+func constraintHash(constraint symbolic.SymbolicExpression) uint64 {
+	return symbolic.HashCombine(0, constraint.Hash())
+}
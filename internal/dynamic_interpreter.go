@@ -1,6 +1,11 @@
 package internal
 
 import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+
 	"golang.org/x/tools/go/ssa"
 	"symbolic-execution-course/internal/memory"
 	"symbolic-execution-course/internal/symbolic"
@@ -9,26 +14,470 @@ import (
 type Interpreter struct {
 	CallStack     []CallStackFrame
 	Analyser      *Analyser
-	PathCondition symbolic.SymbolicExpression
+	PathCondition *symbolic.PathConstraints
 	Heap          memory.Memory
+
+	// Status показывает, завершился ли путь, и если да, то возвратом, паникой
+	// или прерыванием анализа. PanicValue — описание значения, переданного в panic,
+	// AbortReason — причина, по которой путь не удалось исследовать до конца
+	Status      Status
+	PanicValue  string
+	AbortReason string
+}
+
+// Status — состояние исполнения пути
+type Status int
+
+const (
+	Running Status = iota
+	Returned
+	Panicked
+	// Aborted — путь не исследован до конца: интерпретатор не поддерживает очередную инструкцию
+	Aborted
+)
+
+// String возвращает строковое представление состояния
+func (status Status) String() string {
+	switch status {
+	case Running:
+		return "running"
+	case Returned:
+		return "returned"
+	case Panicked:
+		return "panicked"
+	case Aborted:
+		return "aborted"
+	default:
+		return "unknown"
+	}
 }
 
 type CallStackFrame struct {
 	Function    *ssa.Function
 	LocalMemory map[string]symbolic.SymbolicExpression
 	ReturnValue symbolic.SymbolicExpression
+
+	// Текущая позиция исполнения во фрейме
+	Block         *ssa.BasicBlock
+	PreviousBlock *ssa.BasicBlock
+	InstrIndex    int
+}
+
+// NewInterpreter создаёт начальное состояние для анализа функции,
+// параметры которой представлены символьными значениями. Если параметр
+// не удаётся представить, состояние сразу прерывается
+func NewInterpreter(analyser *Analyser, function *ssa.Function) (interpreter Interpreter) {
+	interpreter = Interpreter{
+		Analyser: analyser,
+		Heap:     memory.NewSymbolicMemory(),
+	}
+	defer interpreter.recoverUnsupported(nil)
+
+	frame := CallStackFrame{
+		Function:    function,
+		LocalMemory: make(map[string]symbolic.SymbolicExpression),
+		Block:       function.Blocks[0],
+	}
+	interpreter.CallStack = []CallStackFrame{frame}
+	for _, param := range function.Params {
+		frame.LocalMemory[param.Name()] = interpreter.newParameter(param.Name(), param.Type())
+	}
+	interpreter.constrainReferences(function)
+
+	return interpreter
 }
 
-func (interpreter *Interpreter) interpretDynamically(element ssa.Instruction) []Interpreter {
-	switch element.(type) {
-	// TODO implement me
+// interpretDynamically исполняет инструкцию element и возвращает полученные состояния.
+// Пустой результат означает, что путь недостижим или не может быть продолжен
+func (interpreter *Interpreter) interpretDynamically(element ssa.Instruction) (next []Interpreter) {
+	defer interpreter.recoverUnsupported(&next)
+
+	frame := interpreter.frame()
+	if !interpreter.checkRuntimeErrors(element) {
+		return nil
+	}
+
+	switch instr := element.(type) {
+	case *ssa.BinOp:
+		interpreter.assign(instr.Name(), interpreter.interpretBinOp(instr))
+	case *ssa.UnOp:
+		interpreter.assign(instr.Name(), interpreter.interpretUnOp(instr))
+	case *ssa.Convert:
+		interpreter.assign(instr.Name(), interpreter.interpretConvert(instr))
+	case *ssa.Alloc:
+		interpreter.assign(instr.Name(), interpreter.interpretAlloc(instr))
+	case *ssa.FieldAddr:
+		interpreter.assign(instr.Name(), interpreter.interpretFieldAddr(instr))
+	case *ssa.Field:
+		interpreter.assign(instr.Name(), interpreter.interpretField(instr))
+	case *ssa.IndexAddr:
+		interpreter.assign(instr.Name(), interpreter.interpretIndexAddr(instr))
+	case *ssa.Index:
+		interpreter.assign(instr.Name(), interpreter.interpretIndex(instr))
+	case *ssa.Store:
+		interpreter.interpretStore(instr)
+	case *ssa.Phi:
+		interpreter.interpretPhis()
+		return []Interpreter{*interpreter}
+	case *ssa.Jump:
+		interpreter.jumpTo(frame.Block.Succs[0])
+		return []Interpreter{*interpreter}
+	case *ssa.If:
+		return interpreter.interpretIf(instr)
+	case *ssa.Call:
+		return interpreter.interpretCall(instr)
+	case *ssa.Extract:
+		interpreter.assign(instr.Name(), interpreter.interpretExtract(instr))
+	case *ssa.Return:
+		return interpreter.interpretReturn(instr)
+	case *ssa.Panic:
+		return interpreter.interpretPanic(instr)
+	case *ssa.MakeInterface:
+		// Интерфейсы не моделируются: значение panic описывается по операнду MakeInterface
+		if !onlyPanics(instr) {
+			return interpreter.abort(fmt.Sprintf("неподдерживаемая инструкция %T: %s", element, element.String()))
+		}
+	case *ssa.DebugRef:
+	default:
+		return interpreter.abort(fmt.Sprintf("неподдерживаемая инструкция %T: %s", element, element.String()))
 	}
-	panic("implement me")
+
+	frame.InstrIndex++
+	return []Interpreter{*interpreter}
+}
+
+// abort завершает путь, который не удаётся исследовать дальше. Такой путь не отбрасывается,
+// а попадает в результаты анализа, чтобы было видно, что исследованы не все пути
+func (interpreter *Interpreter) abort(reason string) []Interpreter {
+	interpreter.Status = Aborted
+	interpreter.AbortReason = reason
+	return []Interpreter{*interpreter}
 }
 
+// unsupported — причина прерывания пути, обнаруженная при вычислении операнда.
+// Вычисление значений не возвращает ошибок, поэтому неподдерживаемое значение
+// сообщается паникой unsupported, которую recoverUnsupported превращает в abort
+type unsupported string
+
+// recoverUnsupported прерывает путь при панике unsupported и записывает
+// прерванное состояние в next, если он задан. Остальные паники не перехватываются.
+// Вызывается только через defer
+func (interpreter *Interpreter) recoverUnsupported(next *[]Interpreter) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	reason, ok := recovered.(unsupported)
+	if !ok {
+		panic(recovered)
+	}
+	aborted := interpreter.abort(string(reason))
+	if next != nil {
+		*next = aborted
+	}
+}
+
+// onlyPanics сообщает, используется ли значение только как аргумент panic
+func onlyPanics(value ssa.Value) bool {
+	for _, referrer := range *value.Referrers() {
+		if _, ok := referrer.(*ssa.Panic); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveExpression возвращает символьное выражение для значения SSA
 func (interpreter *Interpreter) resolveExpression(value ssa.Value) symbolic.SymbolicExpression {
-	switch value.(type) {
-	// TODO implement me
+	switch v := value.(type) {
+	case *ssa.Const:
+		if v.Value != nil && v.Value.Kind() == constant.String {
+			return interpreter.Analyser.stringConstant(constant.StringVal(v.Value))
+		}
+		return resolveConstant(v)
+	default:
+		expr, exists := interpreter.frame().LocalMemory[value.Name()]
+		if !exists {
+			panic(unsupported(fmt.Sprintf("неподдерживаемое значение %T: %s", value, value.String())))
+		}
+		return expr
+	}
+}
+
+// stringConstant возвращает непрозрачное значение строки text. Строки не моделируются:
+// равные строки получают равные значения, а пустая строка — нулевое значение строкового типа.
+// Текст запоминается, чтобы вывести его в сгенерированном тесте
+func (analyser *Analyser) stringConstant(text string) symbolic.SymbolicExpression {
+	var value int64
+	if text != "" {
+		value = int64(symbolic.HashString(text))
+	}
+	analyser.Strings[value] = text
+	return symbolic.NewIntConstant(value)
+}
+
+func resolveConstant(value *ssa.Const) symbolic.SymbolicExpression {
+	exprType := symbolicType(value.Type())
+	if value.Value == nil {
+		return zeroValue(value.Type())
+	}
+
+	switch value.Value.Kind() {
+	case constant.Bool:
+		return symbolic.NewBoolConstant(constant.BoolVal(value.Value))
+	case constant.Int:
+		if exprType.IsFloat() {
+			v, _ := constant.Float64Val(value.Value)
+			return symbolic.NewFloatConstant(v, exprType)
+		}
+		return newIntegerConstant(value.Value, exprType)
+	case constant.Float:
+		v, _ := constant.Float64Val(value.Value)
+		return symbolic.NewFloatConstant(v, exprType)
+	default:
+		panic(unsupported(fmt.Sprintf("неподдерживаемая константа %s", value.String())))
+	}
+}
+
+// newIntegerConstant создаёт целочисленную константу типа exprType
+func newIntegerConstant(value constant.Value, exprType symbolic.ExpressionType) symbolic.SymbolicExpression {
+	v, exact := constant.Int64Val(value)
+	if !exact {
+		u, _ := constant.Uint64Val(value)
+		v = int64(u)
+	}
+	if exprType.IsBitVector() {
+		return symbolic.NewBitVecConstant(v, exprType)
+	}
+	return symbolic.NewIntConstant(v)
+}
+
+var binaryOperators = map[token.Token]symbolic.BinaryOperator{
+	token.ADD:     symbolic.ADD,
+	token.SUB:     symbolic.SUB,
+	token.MUL:     symbolic.MUL,
+	token.QUO:     symbolic.DIV,
+	token.REM:     symbolic.MOD,
+	token.EQL:     symbolic.EQ,
+	token.NEQ:     symbolic.NE,
+	token.LSS:     symbolic.LT,
+	token.LEQ:     symbolic.LE,
+	token.GTR:     symbolic.GT,
+	token.GEQ:     symbolic.GE,
+	token.AND:     symbolic.BIT_AND,
+	token.OR:      symbolic.BIT_OR,
+	token.XOR:     symbolic.BIT_XOR,
+	token.AND_NOT: symbolic.BIT_AND_NOT,
+	token.SHL:     symbolic.SHL,
+	token.SHR:     symbolic.SHR,
+}
+
+func (interpreter *Interpreter) interpretBinOp(instr *ssa.BinOp) symbolic.SymbolicExpression {
+	op, exists := binaryOperators[instr.Op]
+	if !exists {
+		panic(unsupported(fmt.Sprintf("неподдерживаемый бинарный оператор %s: %s", instr.Op, instr.String())))
+	}
+	if isString(instr.X.Type()) {
+		panic(unsupported(fmt.Sprintf("неподдерживаемая операция над строками %s", instr.String())))
+	}
+	left := interpreter.resolveExpression(instr.X)
+	right := interpreter.resolveExpression(instr.Y)
+	if l, ok := left.(*pointer); ok {
+		if r, ok := right.(*pointer); ok && (op == symbolic.EQ || op == symbolic.NE) {
+			equal := pointerEquality(l, r)
+			if op == symbolic.NE {
+				return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{equal}, symbolic.NOT)
+			}
+			return equal
+		}
+	}
+	return symbolic.NewBinaryOperation(left, right, op)
+}
+
+func (interpreter *Interpreter) interpretUnOp(instr *ssa.UnOp) symbolic.SymbolicExpression {
+	if instr.Op == token.MUL {
+		return interpreter.load(interpreter.resolvePointer(instr.X), instr.Type())
+	}
+
+	operand := interpreter.resolveExpression(instr.X)
+	switch instr.Op {
+	case token.SUB:
+		return symbolic.NewUnaryOperation(operand, symbolic.UNARY_MINUS)
+	case token.NOT:
+		return symbolic.NewUnaryOperation(operand, symbolic.UNARY_NOT)
+	case token.XOR:
+		return symbolic.NewUnaryOperation(operand, symbolic.UNARY_COMPLEMENT)
+	default:
+		panic(unsupported(fmt.Sprintf("неподдерживаемый унарный оператор %s: %s", instr.Op, instr.String())))
+	}
+}
+
+func (interpreter *Interpreter) interpretConvert(instr *ssa.Convert) symbolic.SymbolicExpression {
+	operand := interpreter.resolveExpression(instr.X)
+	target := symbolicType(instr.Type())
+	if !operand.Type().IsNumeric() || !target.IsNumeric() || isString(instr.X.Type()) || isString(instr.Type()) {
+		panic(unsupported(fmt.Sprintf("неподдерживаемое преобразование %s", instr.String())))
+	}
+	return symbolic.NewConversion(operand, target)
+}
+
+// interpretPhis одновременно вычисляет все φ-функции в начале блока
+// по значениям, пришедшим из предыдущего блока
+func (interpreter *Interpreter) interpretPhis() {
+	frame := interpreter.frame()
+	predIndex := -1
+	for i, pred := range frame.Block.Preds {
+		if pred == frame.PreviousBlock {
+			predIndex = i
+			break
+		}
+	}
+	if predIndex < 0 {
+		panic(fmt.Sprintf("Блок %s не является предшественником %s", frame.PreviousBlock, frame.Block))
+	}
+
+	values := make(map[string]symbolic.SymbolicExpression)
+	for ; frame.InstrIndex < len(frame.Block.Instrs); frame.InstrIndex++ {
+		phi, ok := frame.Block.Instrs[frame.InstrIndex].(*ssa.Phi)
+		if !ok {
+			break
+		}
+		values[phi.Name()] = interpreter.resolveExpression(phi.Edges[predIndex])
+	}
+	for name, value := range values {
+		frame.LocalMemory[name] = value
+	}
+}
+
+// interpretIf разветвляет состояние по условию перехода,
+// оставляя только выполнимые ветви
+func (interpreter *Interpreter) interpretIf(instr *ssa.If) []Interpreter {
+	condition := symbolic.Simplify(interpreter.resolveExpression(instr.Cond))
+	succs := interpreter.frame().Block.Succs
+
+	if value, ok := condition.(*symbolic.BoolConstant); ok {
+		if value.Value {
+			interpreter.jumpTo(succs[0])
+		} else {
+			interpreter.jumpTo(succs[1])
+		}
+		return []Interpreter{*interpreter}
+	}
+
+	negation := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{condition}, symbolic.NOT)
+
+	var result []Interpreter
+	for i, branchCondition := range []symbolic.SymbolicExpression{condition, negation} {
+		pathCondition := conjunction(interpreter.PathCondition, branchCondition)
+		if !interpreter.Analyser.isSatisfiable(pathCondition) {
+			continue
+		}
+		state := interpreter.fork()
+		state.PathCondition = pathCondition
+		state.jumpTo(succs[i])
+		result = append(result, state)
+	}
+	return result
+}
+
+// jumpTo передаёт управление в начало блока block
+func (interpreter *Interpreter) jumpTo(block *ssa.BasicBlock) {
+	frame := interpreter.frame()
+	frame.PreviousBlock = frame.Block
+	frame.Block = block
+	frame.InstrIndex = 0
+}
+
+// fork создаёт копию состояния с независимыми стеком вызовов и кучей
+func (interpreter *Interpreter) fork() Interpreter {
+	callStack := make([]CallStackFrame, len(interpreter.CallStack))
+	for i, frame := range interpreter.CallStack {
+		localMemory := make(map[string]symbolic.SymbolicExpression, len(frame.LocalMemory))
+		for name, value := range frame.LocalMemory {
+			localMemory[name] = value
+		}
+		frame.LocalMemory = localMemory
+		callStack[i] = frame
+	}
+
+	forked := *interpreter
+	forked.CallStack = callStack
+	forked.Heap = interpreter.Heap.Fork()
+	return forked
+}
+
+// conjunction добавляет упрощённое условие к условию пути.
+// Исходное условие пути не изменяется и остаётся общим с другими состояниями
+func conjunction(pathCondition *symbolic.PathConstraints, condition symbolic.SymbolicExpression) *symbolic.PathConstraints {
+	return pathCondition.Add(symbolic.Simplify(condition))
+}
+
+// frame возвращает верхний фрейм стека вызовов
+func (interpreter *Interpreter) frame() *CallStackFrame {
+	return &interpreter.CallStack[len(interpreter.CallStack)-1]
+}
+
+// assign записывает в регистр name интернированное значение value
+func (interpreter *Interpreter) assign(name string, value symbolic.SymbolicExpression) {
+	interpreter.frame().LocalMemory[name] = interpreter.Analyser.Expressions.Intern(value)
+}
+
+// currentInstruction возвращает инструкцию, которая будет исполнена следующей
+func (interpreter *Interpreter) currentInstruction() ssa.Instruction {
+	frame := interpreter.frame()
+	return frame.Block.Instrs[frame.InstrIndex]
+}
+
+// symbolicType сопоставляет типу Go тип символьного выражения
+func symbolicType(tpe types.Type) symbolic.ExpressionType {
+	switch t := tpe.Underlying().(type) {
+	case *types.Basic:
+		return basicType(t)
+	case *types.Pointer:
+		return symbolic.RefType
+	case *types.Array, *types.Slice:
+		return symbolic.ArrayType
+	case *types.Struct:
+		return symbolic.StructType
+	default:
+		return symbolic.IntType
+	}
+}
+
+// isString сообщает, является ли tpe строковым типом. Строки представлены
+// непрозрачными значениями, поэтому операции над ними не моделируются
+func isString(tpe types.Type) bool {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// basicType сопоставляет базовому типу Go тип символьного выражения.
+// Целые типы Go представляются битовыми векторами соответствующей ширины
+func basicType(t *types.Basic) symbolic.ExpressionType {
+	switch t.Kind() {
+	case types.Bool, types.UntypedBool:
+		return symbolic.BoolType
+	case types.Int8:
+		return symbolic.Int8Type
+	case types.Int16:
+		return symbolic.Int16Type
+	case types.Int32:
+		return symbolic.Int32Type
+	case types.Int, types.Int64:
+		return symbolic.Int64Type
+	case types.Uint8:
+		return symbolic.Uint8Type
+	case types.Uint16:
+		return symbolic.Uint16Type
+	case types.Uint32:
+		return symbolic.Uint32Type
+	case types.Uint, types.Uint64, types.Uintptr:
+		return symbolic.Uint64Type
+	case types.Float32:
+		return symbolic.Float32Type
+	case types.Float64, types.UntypedFloat:
+		return symbolic.Float64Type
+	default:
+		return symbolic.IntType
 	}
-	panic("implement me")
 }
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// pointer представляет адрес значения в куче: объект Base и смещение поля в нём.
+// Вложенные структуры хранятся в объекте «плоско», поэтому Field — номер
+// поля-листа в развёрнутой структуре. Если Index не nil, поле хранит массив,
+// а указатель ссылается на его элемент с индексом Index
+type pointer struct {
+	Base  *symbolic.Ref
+	Field int
+	Index symbolic.SymbolicExpression
+}
+
+func (p *pointer) Type() symbolic.ExpressionType {
+	return symbolic.RefType
+}
+
+func (p *pointer) String() string {
+	result := "&" + p.Base.String()
+	if p.Field != 0 {
+		result = fmt.Sprintf("%s.%d", result, p.Field)
+	}
+	if p.Index != nil {
+		result = fmt.Sprintf("%s[%s]", result, p.Index.String())
+	}
+	return result
+}
+
+// Accept обходит базовую ссылку, которая совпадает с адресом указателя на начало объекта.
+// Адрес поля или элемента не выражается одной ссылкой, поэтому такие указатели
+// сравниваются через pointerEquality и в выражения не попадают
+func (p *pointer) Accept(visitor symbolic.Visitor) interface{} {
+	if p.Field != 0 || p.Index != nil {
+		panic(fmt.Sprintf("Адрес %s не является адресом объекта", p.String()))
+	}
+	return p.Base.Accept(visitor)
+}
+
+func (p *pointer) Equals(other symbolic.SymbolicExpression) bool {
+	o, ok := other.(*pointer)
+	return ok && p.Field == o.Field && symbolic.Equal(p.Base, o.Base) && symbolic.Equal(p.Index, o.Index)
+}
+
+func (p *pointer) Hash() uint64 {
+	return symbolic.HashCombine(symbolic.HashString("pointer"), p.Base.Hash(), uint64(p.Field), symbolic.HashOf(p.Index))
+}
+
+// offset возвращает адрес поля-листа field относительно указателя
+func (p *pointer) offset(field int) *pointer {
+	return &pointer{Base: p.Base, Field: p.Field + field, Index: p.Index}
+}
+
+// pointerEquality строит условие совпадения адресов: указатели равны, если равны
+// базовые объекты, смещения полей и индексы элементов
+func pointerEquality(left, right *pointer) symbolic.SymbolicExpression {
+	if left.Field != right.Field || (left.Index == nil) != (right.Index == nil) {
+		return symbolic.NewBoolConstant(false)
+	}
+	sameBase := symbolic.NewBinaryOperation(left.Base, right.Base, symbolic.EQ)
+	if left.Index == nil {
+		return sameBase
+	}
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		sameBase,
+		symbolic.NewBinaryOperation(left.Index, right.Index, symbolic.EQ),
+	}, symbolic.AND)
+}
+
+// flatField описывает поле-лист развёрнутой структуры
+type flatField struct {
+	Name   string
+	Type   symbolic.ExpressionType
+	GoType types.Type
+}
+
+// flatFields разворачивает тип в список полей-листьев. Не-структуры дают одно поле
+func flatFields(tpe types.Type, prefix string) []flatField {
+	structType, ok := tpe.Underlying().(*types.Struct)
+	if !ok {
+		return []flatField{{Name: prefix, Type: symbolicType(tpe), GoType: tpe}}
+	}
+
+	var fields []flatField
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		name := field.Name()
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fields = append(fields, flatFields(field.Type(), name)...)
+	}
+	return fields
+}
+
+// fieldOffset возвращает смещение поля index в развёрнутой структуре
+func fieldOffset(structType *types.Struct, index int) int {
+	offset := 0
+	for i := 0; i < index; i++ {
+		offset += len(flatFields(structType.Field(i).Type(), ""))
+	}
+	return offset
+}
+
+func fieldTypes(fields []flatField) []symbolic.ExpressionType {
+	result := make([]symbolic.ExpressionType, len(fields))
+	for i, field := range fields {
+		result[i] = field.Type
+	}
+	return result
+}
+
+// newParameter создаёт символьное значение параметра функции.
+// Указатели становятся символьными ссылками, которые могут совпадать между собой,
+// структуры — объектами с символьными полями
+func (interpreter *Interpreter) newParameter(name string, tpe types.Type) symbolic.SymbolicExpression {
+	switch t := tpe.Underlying().(type) {
+	case *types.Pointer:
+		fields := flatFields(t.Elem(), "")
+		return &pointer{Base: interpreter.Heap.AllocateSymbolic(t.Elem().String(), fieldTypes(fields))}
+	case *types.Slice:
+		return interpreter.newSliceParameter(name, t)
+	case *types.Array:
+		return inputArray(name, t.Elem())
+	case *types.Struct:
+		fields := flatFields(t, name)
+		ref := interpreter.Heap.AllocateStruct(len(fields))
+		for i, field := range fields {
+			interpreter.Heap.AssignField(ref, i, symbolic.NewSymbolicVariable(field.Name, field.Type))
+		}
+		return ref
+	default:
+		return symbolic.NewSymbolicVariable(name, symbolicType(tpe))
+	}
+}
+
+// constrainReferences ограничивает символьные ссылки параметров: каждая равна nil
+// или адресу одного из символьных объектов того же типа. Без этого решатель мог бы
+// приравнять входную ссылку адресу объекта, выделенного при исполнении
+func (interpreter *Interpreter) constrainReferences(function *ssa.Function) {
+	type object struct {
+		typeName string
+		ref      *symbolic.Ref
+	}
+	var objects []object
+	for _, param := range function.Params {
+		if address, ok := interpreter.CallStack[0].LocalMemory[param.Name()].(*pointer); ok {
+			objects = append(objects, object{param.Type().Underlying().(*types.Pointer).Elem().String(), address.Base})
+		}
+	}
+
+	for _, obj := range objects {
+		options := []symbolic.SymbolicExpression{symbolic.NewBinaryOperation(obj.ref, symbolic.NilRef(), symbolic.EQ)}
+		for _, other := range objects {
+			if other.typeName == obj.typeName {
+				address := symbolic.NewRef(other.ref.ID, symbolic.StructType)
+				options = append(options, symbolic.NewBinaryOperation(obj.ref, address, symbolic.EQ))
+			}
+		}
+		interpreter.PathCondition = conjunction(interpreter.PathCondition, symbolic.NewLogicalOperation(options, symbolic.OR))
+	}
+}
+
+// interpretAlloc выделяет в куче объект с нулевыми значениями полей
+func (interpreter *Interpreter) interpretAlloc(instr *ssa.Alloc) symbolic.SymbolicExpression {
+	elem := instr.Type().Underlying().(*types.Pointer).Elem()
+	fields := flatFields(elem, "")
+
+	ref := interpreter.Heap.AllocateStruct(len(fields))
+	for i, field := range fields {
+		interpreter.Heap.AssignField(ref, i, zeroValue(field.GoType))
+	}
+	return &pointer{Base: ref}
+}
+
+func (interpreter *Interpreter) interpretFieldAddr(instr *ssa.FieldAddr) symbolic.SymbolicExpression {
+	base := interpreter.resolvePointer(instr.X)
+	if base.Index != nil {
+		panic(unsupported(fmt.Sprintf("неподдерживаемый адрес поля элемента массива %s", instr.String())))
+	}
+	structType := instr.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct)
+	return &pointer{Base: base.Base, Field: base.Field + fieldOffset(structType, instr.Field)}
+}
+
+func (interpreter *Interpreter) interpretField(instr *ssa.Field) symbolic.SymbolicExpression {
+	value, ok := interpreter.resolveExpression(instr.X).(*symbolic.Ref)
+	if !ok {
+		panic(unsupported(fmt.Sprintf("неподдерживаемая структура %s: %s", instr.X.Name(), instr.String())))
+	}
+	structType := instr.X.Type().Underlying().(*types.Struct)
+	return interpreter.load(&pointer{Base: value, Field: fieldOffset(structType, instr.Field)}, instr.Type())
+}
+
+func (interpreter *Interpreter) interpretStore(instr *ssa.Store) {
+	address := interpreter.resolvePointer(instr.Addr)
+	value := interpreter.resolveExpression(instr.Val)
+
+	if _, ok := instr.Val.Type().Underlying().(*types.Struct); ok {
+		// Структуры копируются по значению
+		count := len(flatFields(instr.Val.Type(), ""))
+		interpreter.copyFields(address, &pointer{Base: value.(*symbolic.Ref)}, count)
+		return
+	}
+	interpreter.storeValue(address, value)
+}
+
+// load читает значение типа tpe по адресу. Структура читается как новая копия
+func (interpreter *Interpreter) load(address *pointer, tpe types.Type) symbolic.SymbolicExpression {
+	if _, ok := tpe.Underlying().(*types.Struct); ok {
+		count := len(flatFields(tpe, ""))
+		copied := interpreter.Heap.AllocateStruct(count)
+		interpreter.copyFields(&pointer{Base: copied}, address, count)
+		return copied
+	}
+	return interpreter.loadValue(address)
+}
+
+// loadValue читает значение поля-листа или элемента массива по адресу
+func (interpreter *Interpreter) loadValue(address *pointer) symbolic.SymbolicExpression {
+	value := interpreter.Heap.GetFieldValue(address.Base, address.Field)
+	if address.Index != nil {
+		return symbolic.NewArraySelect(value, address.Index)
+	}
+	return value
+}
+
+// storeValue записывает значение поля-листа или элемента массива по адресу
+func (interpreter *Interpreter) storeValue(address *pointer, value symbolic.SymbolicExpression) {
+	if address.Index != nil {
+		array := interpreter.Heap.GetFieldValue(address.Base, address.Field)
+		value = symbolic.NewArrayStore(array, address.Index, value)
+	}
+	interpreter.Heap.AssignField(address.Base, address.Field, value)
+}
+
+func (interpreter *Interpreter) copyFields(destination, source *pointer, count int) {
+	for i := 0; i < count; i++ {
+		value := interpreter.Heap.GetFieldValue(source.Base, source.Field+i)
+		interpreter.Heap.AssignField(destination.Base, destination.Field+i, value)
+	}
+}
+
+func (interpreter *Interpreter) resolvePointer(value ssa.Value) *pointer {
+	address, ok := interpreter.resolveExpression(value).(*pointer)
+	if !ok {
+		panic(unsupported(fmt.Sprintf("неподдерживаемый указатель %s: %s", value.Name(), value.String())))
+	}
+	return address
+}
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// IndependentSolver проверяет условия путей по частям, независимым по переменным.
+// Условие выполнимо, только если выполнима каждая часть, а модели частей
+// объединяются, поскольку части не имеют общих переменных.
+// Результаты частей запоминаются в кэше запросов, поэтому в решатель попадает только часть,
+// связанная с новым условием ветвления, а остальные берутся из кэша.
+// Каждая часть решается в своей инкрементальной сессии, поэтому чередование
+// запросов к разным частям не вытесняет из решателя их общие префиксы
+type IndependentSolver struct {
+	Cache *CounterexampleCache
+
+	translator *translator.Z3Translator
+	sessions   []partitionSession // от недавно использованной к давно использованной
+	empty      *z3.Model          // модель без переменных, создаётся при первом решении
+	stats      IndependenceStats
+}
+
+// MaxPartitionSessions ограничивает число сессий решателя. Сессия давно
+// не встречавшейся части передаётся новой части
+const MaxPartitionSessions = 16
+
+// partitionSession — сессия решателя для частей, начинающихся с конъюнкта first
+type partitionSession struct {
+	first   symbolic.SymbolicExpression
+	session *SolverSession
+}
+
+// IndependenceStats — статистика разбиения условий путей на независимые части
+type IndependenceStats struct {
+	Queries    int // число запросов
+	Partitions int // число независимых частей во всех запросах
+	Solved     int // число частей, отправленных в решатель
+	Cached     int // число частей, взятых из кэша
+	Sliced     int // число конъюнктов, не отправленных в решатель благодаря разбиению
+}
+
+func NewIndependentSolver(z3Translator *translator.Z3Translator, cache *CounterexampleCache) *IndependentSolver {
+	return &IndependentSolver{Cache: cache, translator: z3Translator}
+}
+
+// Stats возвращает статистику разбиения
+func (solver *IndependentSolver) Stats() IndependenceStats {
+	return solver.stats
+}
+
+// SessionStats возвращает суммарную статистику сессий решателя
+func (solver *IndependentSolver) SessionStats() SessionStats {
+	var total SessionStats
+	for _, entry := range solver.sessions {
+		stats := entry.session.Stats()
+		total.Checks += stats.Checks
+		total.Asserted += stats.Asserted
+		total.Reused += stats.Reused
+		total.Popped += stats.Popped
+	}
+	return total
+}
+
+// session возвращает сессию части с первым конъюнктом first. Первый конъюнкт части
+// сохраняется, пока часть растёт вдоль пути, поэтому следующий запрос к ней
+// продолжает префикс, уже утверждённый в этой сессии
+func (solver *IndependentSolver) session(first symbolic.SymbolicExpression) *SolverSession {
+	index := -1
+	for i, entry := range solver.sessions {
+		if symbolic.Equal(entry.first, first) {
+			index = i
+			break
+		}
+	}
+
+	var entry partitionSession
+	switch {
+	case index >= 0:
+		entry = solver.sessions[index]
+		solver.sessions = append(solver.sessions[:index], solver.sessions[index+1:]...)
+	case len(solver.sessions) < MaxPartitionSessions:
+		entry = partitionSession{first: first, session: NewSolverSession(solver.translator)}
+	default:
+		// Утверждения прежней части снимет следующая проверка
+		entry = solver.sessions[len(solver.sessions)-1]
+		solver.sessions = solver.sessions[:len(solver.sessions)-1]
+		entry.first = first
+	}
+	solver.sessions = append([]partitionSession{entry}, solver.sessions...)
+	return entry.session
+}
+
+// Check проверяет выполнимость условия пути
+func (solver *IndependentSolver) Check(pathCondition *symbolic.PathConstraints) (bool, error) {
+	model, err := solver.Solve(pathCondition)
+	return model != nil, err
+}
+
+// Solve ищет модель условия пути. Для невыполнимого условия возвращается nil
+func (solver *IndependentSolver) Solve(pathCondition *symbolic.PathConstraints) (*Model, error) {
+	solver.stats.Queries++
+
+	partitions := pathCondition.Partitions()
+	if len(partitions) == 0 {
+		// Модель пустого условия нужна, чтобы вычислять в ней значения выражений
+		partitions = [][]symbolic.SymbolicExpression{nil}
+	}
+	solver.stats.Partitions += len(partitions)
+
+	model := &Model{translator: solver.translator}
+	for _, partition := range partitions {
+		sat, partModel, err := solver.solvePart(partition, pathCondition.Len())
+		if err != nil {
+			return nil, err
+		}
+		if !sat {
+			return nil, nil
+		}
+		model.parts = append(model.parts, partModel)
+	}
+
+	if solver.empty == nil {
+		empty := z3.NewSolver(solver.translator.GetContext().(*z3.Context))
+		if _, err := empty.Check(); err != nil {
+			return nil, err
+		}
+		solver.empty = empty.Model()
+	}
+	model.empty = solver.empty
+	return model, nil
+}
+
+// solvePart проверяет часть partition условия из total конъюнктов,
+// обращаясь к решателю, только если ответ нельзя получить из кэша
+func (solver *IndependentSolver) solvePart(partition []symbolic.SymbolicExpression, total int) (bool, *ProjectedModel, error) {
+	solved := false
+	sat, model, err := solver.Cache.Check(partition, func() (bool, *z3.Model, error) {
+		solved = true
+		var first symbolic.SymbolicExpression
+		if len(partition) > 0 {
+			first = partition[0]
+		}
+		session := solver.session(first)
+		sat, err := session.Check(partition)
+		if err != nil || !sat {
+			return false, nil, err
+		}
+		return true, session.Model(), nil
+	})
+	if err != nil {
+		return false, nil, err
+	}
+
+	if solved {
+		solver.stats.Solved++
+		solver.stats.Sliced += total - len(partition)
+	} else {
+		solver.stats.Cached++
+	}
+	return sat, model, nil
+}
+
+// Model — модель условия пути, составленная из моделей его независимых частей.
+// Модели частей ограничены их переменными, поэтому значение переменной берётся
+// из модели части, которая её упоминает
+type Model struct {
+	translator *translator.Z3Translator
+	parts      []*ProjectedModel
+	empty      *z3.Model // доопределяет переменные, не упомянутые ни в одной части
+}
+
+// Eval вычисляет значение выражения в модели. Переменные, не упомянутые
+// ни в одной части, получают значения по умолчанию. Если выражение
+// не транслируется в Z3, возвращается nil
+func (model *Model) Eval(expr symbolic.SymbolicExpression) z3.Value {
+	value, err := model.translator.TranslateBound(expr, func(name string, variable z3.Value) z3.Value {
+		for _, part := range model.parts {
+			if value := part.Value(name, variable); value != nil {
+				return value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return model.empty.Eval(value.(z3.Value), true)
+}
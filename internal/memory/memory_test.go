@@ -755,3 +755,285 @@ func TestZ3MemoryModelConsistency(t *testing.T) {
 	t.Logf("Memory model consistency verified with Z3")
 	t.Logf("Final memory state: %s", mem.String())
 }
+
+// checkSat транслирует условие и проверяет его выполнимость в Z3
+func checkSat(t *testing.T, z3Translator *translator.Z3Translator, condition symbolic.SymbolicExpression) bool {
+	t.Helper()
+
+	z3Condition, err := z3Translator.TranslateExpression(condition)
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+
+	ctx := z3Translator.GetContext().(*z3.Context)
+	solver := z3.NewSolver(ctx)
+	solver.Assert(z3Condition.(z3.Bool))
+
+	result, err := solver.Check()
+	if err != nil {
+		t.Fatalf("Error checking satisfiability: %v", err)
+	}
+	return result
+}
+
+func and(conditions ...symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation(conditions, symbolic.AND)
+}
+
+// TestSymbolicIndexWrite тестирует запись по символьному индексу и чтение по конкретному
+func TestSymbolicIndexWrite(t *testing.T) {
+	mem := NewSymbolicMemory()
+	z3Translator := translator.NewZ3Translator()
+	defer z3Translator.Close()
+
+	arr := mem.AllocateArray(3)
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	mem.AssignToArraySymbolic(arr, i, symbolic.NewIntConstant(10))
+
+	value := mem.GetFromArray(arr, 2)
+	isTen := symbolic.NewBinaryOperation(value, symbolic.NewIntConstant(10), symbolic.EQ)
+	notTwo := symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(2), symbolic.NE)
+
+	if !checkSat(t, z3Translator, isTen) {
+		t.Errorf("Expected arr[2] == 10 to be possible, got %s", value.String())
+	}
+	if checkSat(t, z3Translator, and(isTen, notTwo)) {
+		t.Errorf("Expected arr[2] == 10 only when i == 2, got %s", value.String())
+	}
+}
+
+// TestSymbolicIndexPreservesConcreteWrites тестирует, что символьная запись не теряет конкретные
+func TestSymbolicIndexPreservesConcreteWrites(t *testing.T) {
+	mem := NewSymbolicMemory()
+	z3Translator := translator.NewZ3Translator()
+	defer z3Translator.Close()
+
+	arr := mem.Allocate(symbolic.ArrayType)
+	mem.AssignToArray(arr, 0, symbolic.NewIntConstant(5))
+	mem.AssignToArray(arr, 1, symbolic.NewIntConstant(7))
+
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	j := symbolic.NewSymbolicVariable("j", symbolic.IntType)
+	mem.AssignToArraySymbolic(arr, i, symbolic.NewIntConstant(9))
+
+	value := mem.GetFromArraySymbolic(arr, j)
+	one := symbolic.NewIntConstant(1)
+	condition := and(
+		symbolic.NewBinaryOperation(j, one, symbolic.EQ),
+		symbolic.NewBinaryOperation(i, one, symbolic.NE),
+		symbolic.NewBinaryOperation(value, symbolic.NewIntConstant(7), symbolic.NE),
+	)
+	if checkSat(t, z3Translator, condition) {
+		t.Errorf("Expected arr[1] to keep 7 when i != 1, got %s", value.String())
+	}
+
+	overwritten := and(
+		symbolic.NewBinaryOperation(i, j, symbolic.EQ),
+		symbolic.NewBinaryOperation(value, symbolic.NewIntConstant(9), symbolic.NE),
+	)
+	if checkSat(t, z3Translator, overwritten) {
+		t.Errorf("Expected arr[j] == 9 when i == j, got %s", value.String())
+	}
+}
+
+// TestConcreteWriteAfterSymbolicWrite тестирует порядок конкретных и символьных записей
+func TestConcreteWriteAfterSymbolicWrite(t *testing.T) {
+	mem := NewSymbolicMemory()
+	z3Translator := translator.NewZ3Translator()
+	defer z3Translator.Close()
+
+	arr := mem.AllocateArray(5)
+	i := symbolic.NewSymbolicVariable("i", symbolic.Int64Type)
+	mem.AssignToArraySymbolic(arr, i, symbolic.NewBitVecConstant(9, symbolic.Int64Type))
+	mem.AssignToArray(arr, 3, symbolic.NewBitVecConstant(1, symbolic.Int64Type))
+
+	if value := mem.GetFromArray(arr, 3); value.String() != "1" {
+		t.Errorf("Expected arr[3] == 1 after concrete write, got %s", value.String())
+	}
+
+	j := symbolic.NewSymbolicVariable("j", symbolic.Int64Type)
+	value := mem.GetFromArraySymbolic(arr, j)
+	condition := and(
+		symbolic.NewBinaryOperation(j, symbolic.NewBitVecConstant(3, symbolic.Int64Type), symbolic.EQ),
+		symbolic.NewBinaryOperation(value, symbolic.NewBitVecConstant(1, symbolic.Int64Type), symbolic.NE),
+	)
+	if checkSat(t, z3Translator, condition) {
+		t.Errorf("Expected arr[j] == 1 when j == 3, got %s", value.String())
+	}
+}
+
+// TestArrayElementType тестирует, что элементы массива имеют объявленный тип
+func TestArrayElementType(t *testing.T) {
+	mem := NewSymbolicMemory()
+
+	arr := mem.AllocateTypedArray(2, symbolic.Int64Type)
+	if value := mem.GetFromArray(arr, 1); value.Type() != symbolic.Int64Type || value.String() != "0" {
+		t.Errorf("Expected int64 zero element, got %s of type %s", value.String(), value.Type())
+	}
+	if value := mem.GetFromArray(arr, 5); value.Type() != symbolic.Int64Type {
+		t.Errorf("Expected missing element of type int64, got %s", value.Type())
+	}
+	i := symbolic.NewSymbolicVariable("i", symbolic.Int64Type)
+	if value := mem.GetFromArraySymbolic(arr, i); value.Type() != symbolic.Int64Type {
+		t.Errorf("Expected symbolic read of type int64, got %s", value.Type())
+	}
+
+	refs := mem.AllocateTypedArray(1, symbolic.RefType)
+	if value, ok := mem.GetFromArray(refs, 0).(*symbolic.Ref); !ok || value.ID != 0 {
+		t.Errorf("Expected nil reference element, got %s", mem.GetFromArray(refs, 0).String())
+	}
+}
+
+// TestSymbolicReferenceAliasing тестирует запись через ссылки, которые могут совпадать
+func TestSymbolicReferenceAliasing(t *testing.T) {
+	mem := NewSymbolicMemory()
+	z3Translator := translator.NewZ3Translator()
+
+	fieldTypes := []symbolic.ExpressionType{symbolic.IntType}
+	foo1 := mem.AllocateSymbolic("Foo", fieldTypes)
+	foo2 := mem.AllocateSymbolic("Foo", fieldTypes)
+
+	mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
+	mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
+
+	isTwo := symbolic.NewBinaryOperation(mem.GetFieldValue(foo2, 0), symbolic.NewIntConstant(2), symbolic.EQ)
+	isFive := symbolic.NewBinaryOperation(mem.GetFieldValue(foo2, 0), symbolic.NewIntConstant(5), symbolic.EQ)
+	aliased := symbolic.NewBinaryOperation(foo1, foo2, symbolic.EQ)
+	notAliased := symbolic.NewBinaryOperation(foo1, foo2, symbolic.NE)
+
+	if !checkSat(t, z3Translator, and(isTwo, aliased)) {
+		t.Errorf("Expected foo2.a == 2 when foo1 and foo2 are the same object")
+	}
+	if checkSat(t, z3Translator, and(isTwo, notAliased)) {
+		t.Errorf("Expected foo2.a == 2 to be impossible for different objects")
+	}
+	if !checkSat(t, z3Translator, and(isFive, notAliased)) {
+		t.Errorf("Expected foo2.a == 5 for different objects")
+	}
+}
+
+// TestSymbolicReferenceLazyFields тестирует согласованность лениво инициализированных полей
+func TestSymbolicReferenceLazyFields(t *testing.T) {
+	mem := NewSymbolicMemory()
+	z3Translator := translator.NewZ3Translator()
+
+	fieldTypes := []symbolic.ExpressionType{symbolic.IntType, symbolic.BoolType}
+	first := mem.AllocateSymbolic("Foo", fieldTypes)
+	second := mem.AllocateSymbolic("Foo", fieldTypes)
+	other := mem.AllocateSymbolic("Bar", fieldTypes)
+
+	if mem.GetFieldValue(first, 1).Type() != symbolic.BoolType {
+		t.Errorf("Expected lazily created field to have declared type")
+	}
+
+	firstValue := mem.GetFieldValue(first, 0)
+	secondValue := mem.GetFieldValue(second, 0)
+	aliased := symbolic.NewBinaryOperation(first, second, symbolic.EQ)
+	differentValues := symbolic.NewBinaryOperation(firstValue, secondValue, symbolic.NE)
+
+	if checkSat(t, z3Translator, and(aliased, differentValues)) {
+		t.Errorf("Expected the same object to have the same field values")
+	}
+	if !checkSat(t, z3Translator, differentValues) {
+		t.Errorf("Expected different objects to have independent field values")
+	}
+
+	mem.AssignField(first, 0, symbolic.NewIntConstant(7))
+	if _, ok := mem.GetFieldValue(other, 0).(*symbolic.SymbolicVariable); !ok {
+		t.Errorf("Expected objects of different types not to alias")
+	}
+}
+
+// TestForkIsolation тестирует независимость памяти после ветвления
+func TestForkIsolation(t *testing.T) {
+	mem := NewSymbolicMemory()
+	structRef := mem.AllocateStruct(2)
+	arrayRef := mem.AllocateArray(3)
+	mem.AssignField(structRef, 0, symbolic.NewIntConstant(1))
+
+	forked := mem.Fork()
+	forked.AssignField(structRef, 0, symbolic.NewIntConstant(2))
+	forked.AssignToArray(arrayRef, 1, symbolic.NewIntConstant(5))
+	mem.AssignToArraySymbolic(arrayRef, symbolic.NewSymbolicVariable("i", symbolic.IntType), symbolic.NewIntConstant(7))
+
+	if value := mem.GetFieldValue(structRef, 0).String(); value != "1" {
+		t.Errorf("Expected original field to stay 1, got %s", value)
+	}
+	if value := forked.GetFieldValue(structRef, 0).String(); value != "2" {
+		t.Errorf("Expected forked field to be 2, got %s", value)
+	}
+	if value := mem.GetFromArray(arrayRef, 1).String(); value == "5" {
+		t.Errorf("Write to forked memory leaked into original")
+	}
+	if value := forked.GetFromArray(arrayRef, 1).String(); value != "5" {
+		t.Errorf("Expected forked element to be 5, got %s", value)
+	}
+
+	newRef := forked.AllocateStruct(1)
+	otherRef := mem.AllocateStruct(1)
+	if newRef.ID != otherRef.ID {
+		t.Errorf("Expected both branches to continue allocation from the same ID")
+	}
+	forked.AssignField(newRef, 0, symbolic.NewIntConstant(3))
+	if value := mem.GetFieldValue(otherRef, 0).String(); value != "0" {
+		t.Errorf("Allocation in forked memory leaked into original: %s", value)
+	}
+}
+
+// TestForkSymbolicObjects тестирует ленивую инициализацию символьных объектов после ветвления
+func TestForkSymbolicObjects(t *testing.T) {
+	mem := NewSymbolicMemory()
+	fieldTypes := []symbolic.ExpressionType{symbolic.IntType}
+	foo1 := mem.AllocateSymbolic("Foo", fieldTypes)
+	foo2 := mem.AllocateSymbolic("Foo", fieldTypes)
+
+	forked := mem.Fork()
+	forked.AssignField(foo1, 0, symbolic.NewIntConstant(2))
+
+	if _, ok := mem.GetFieldValue(foo2, 0).(*symbolic.SymbolicVariable); !ok {
+		t.Errorf("Expected write in forked memory not to affect original aliases")
+	}
+	if _, ok := forked.GetFieldValue(foo2, 0).(*symbolic.IteExpression); !ok {
+		t.Errorf("Expected forked memory to see a possibly aliased write")
+	}
+}
+
+// TestPersistentMap тестирует персистентное отображение
+func TestPersistentMap(t *testing.T) {
+	var original persistentMap[int]
+	for i := 0; i < 2000; i += 3 {
+		original = original.set(i, i*2)
+	}
+
+	updated := original
+	for i := 0; i < 2000; i += 2 {
+		updated = updated.set(i, -i)
+	}
+
+	for i := 0; i < 2000; i++ {
+		value, ok := original.get(i)
+		if ok != (i%3 == 0) || (ok && value != i*2) {
+			t.Fatalf("Original map changed at key %d: %d, %v", i, value, ok)
+		}
+		value, ok = updated.get(i)
+		switch {
+		case i%2 == 0 && (!ok || value != -i):
+			t.Fatalf("Expected updated value at key %d, got %d, %v", i, value, ok)
+		case i%2 != 0 && ok != (i%3 == 0):
+			t.Fatalf("Unexpected presence of key %d in updated map", i)
+		}
+	}
+
+	previous := -1
+	count := 0
+	updated.forEach(func(key int, value int) {
+		if key <= previous {
+			t.Fatalf("Keys are not ordered: %d after %d", key, previous)
+		}
+		previous = key
+		count++
+	})
+	if count != updated.len() {
+		t.Errorf("Expected %d keys, visited %d", updated.len(), count)
+	}
+}
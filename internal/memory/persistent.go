@@ -0,0 +1,114 @@
+package memory
+
+import "fmt"
+
+const (
+	trieBits  = 5
+	trieWidth = 1 << trieBits
+	trieMask  = trieWidth - 1
+)
+
+// persistentMap — неизменяемое отображение неотрицательных целых ключей в значения,
+// устроенное как префиксное дерево с основанием 32. Запись копирует только путь
+// от корня до листа, поэтому копирование отображения стоит O(1), а запись — O(log n)
+type persistentMap[V any] struct {
+	root  *trieNode[V]
+	shift uint
+	size  int
+}
+
+type trieNode[V any] struct {
+	children [trieWidth]*trieNode[V]
+	values   [trieWidth]V
+	present  uint32
+}
+
+// get возвращает значение по ключу
+func (m persistentMap[V]) get(key int) (V, bool) {
+	var zero V
+	if key < 0 || m.root == nil || key>>(m.shift+trieBits) != 0 {
+		return zero, false
+	}
+
+	node := m.root
+	for shift := m.shift; shift > 0; shift -= trieBits {
+		node = node.children[(key>>shift)&trieMask]
+		if node == nil {
+			return zero, false
+		}
+	}
+
+	index := key & trieMask
+	if node.present&(1<<index) == 0 {
+		return zero, false
+	}
+	return node.values[index], true
+}
+
+// set возвращает новое отображение, в котором ключу key соответствует value
+func (m persistentMap[V]) set(key int, value V) persistentMap[V] {
+	if key < 0 {
+		panic(fmt.Sprintf("Отрицательный ключ %d", key))
+	}
+
+	if m.root == nil {
+		m.root = &trieNode[V]{}
+	}
+	for key>>(m.shift+trieBits) != 0 {
+		root := &trieNode[V]{}
+		root.children[0] = m.root
+		m.root = root
+		m.shift += trieBits
+	}
+
+	var added bool
+	m.root, added = m.root.set(m.shift, key, value)
+	if added {
+		m.size++
+	}
+	return m
+}
+
+func (node *trieNode[V]) set(shift uint, key int, value V) (*trieNode[V], bool) {
+	copied := &trieNode[V]{}
+	if node != nil {
+		*copied = *node
+	}
+
+	if shift == 0 {
+		index := key & trieMask
+		added := copied.present&(1<<index) == 0
+		copied.values[index] = value
+		copied.present |= 1 << index
+		return copied, added
+	}
+
+	index := (key >> shift) & trieMask
+	child, added := copied.children[index].set(shift-trieBits, key, value)
+	copied.children[index] = child
+	return copied, added
+}
+
+func (m persistentMap[V]) len() int {
+	return m.size
+}
+
+// forEach обходит элементы в порядке возрастания ключей
+func (m persistentMap[V]) forEach(visit func(key int, value V)) {
+	if m.root != nil {
+		m.root.forEach(m.shift, 0, visit)
+	}
+}
+
+func (node *trieNode[V]) forEach(shift uint, prefix int, visit func(key int, value V)) {
+	for i := 0; i < trieWidth; i++ {
+		key := prefix | i<<shift
+		if shift == 0 {
+			if node.present&(1<<i) != 0 {
+				visit(key, node.values[i])
+			}
+		} else if child := node.children[i]; child != nil {
+			child.forEach(shift-trieBits, key, visit)
+		}
+	}
+}
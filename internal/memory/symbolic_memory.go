@@ -2,7 +2,9 @@ package memory
 
 import (
 	"fmt"
+	"sort"
 	"symbolic-execution-course/internal/symbolic"
+	"sync/atomic"
 )
 
 type Memory interface {
@@ -16,45 +18,154 @@ type Memory interface {
 
 	GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression
 
+	AssignToArraySymbolic(ref *symbolic.Ref, index symbolic.SymbolicExpression, value symbolic.SymbolicExpression)
+
+	GetFromArraySymbolic(ref *symbolic.Ref, index symbolic.SymbolicExpression) symbolic.SymbolicExpression
+
 	AllocateStruct(fieldCount int) *symbolic.Ref
 	AllocateArray(length int) *symbolic.Ref
+
+	// AllocateTypedArray создаёт массив из length нулевых значений типа elemType
+	AllocateTypedArray(length int, elemType symbolic.ExpressionType) *symbolic.Ref
+
+	// AllocateSymbolic создаёт лениво инициализируемую структуру по символьной ссылке,
+	// которая может совпадать с другими символьными ссылками того же типа
+	AllocateSymbolic(typeName string, fieldTypes []symbolic.ExpressionType) *symbolic.Ref
+
+	// InitialFieldValue возвращает значение поля символьного объекта до первой записи
+	// или nil, если поле ни разу не читалось
+	InitialFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression
+
+	// Fork возвращает независимую копию памяти для нового состояния исполнения
+	Fork() Memory
 }
 
+// SymbolicMemory хранит объекты в персистентных отображениях, а сами объекты
+// копируются при первой записи после Fork. Поэтому ветвление стоит O(1),
+// а копируются только те объекты, которые состояние действительно изменяет
 type SymbolicMemory struct {
-	objects      map[int]*MemoryObject
+	objects      persistentMap[*MemoryObject]
 	nextObjectID int
-	aliases      map[int]int // map[aliasID]originalID
+	aliases      persistentMap[int] // map[aliasID]originalID
+	owner        uint64
 }
 
+// lastOwner выдаёт уникальные метки владельцев памяти
+var lastOwner uint64
+
 type MemoryObject struct {
 	Type   symbolic.ExpressionType
 	Fields map[int]symbolic.SymbolicExpression // для структур
 	Elems  map[int]symbolic.SymbolicExpression // для массивов
+
+	// Writes хранит записи в массив в порядке исполнения, начиная с первой записи
+	// по символьному индексу. Elems описывает состояние массива до этой записи
+	Writes []ArrayWrite
+
+	// ElemType — объявленный тип элементов массива. Для массивов без объявленного
+	// типа равен IntType, и тип выводится из записанных значений
+	ElemType symbolic.ExpressionType
+
+	// Symbolic означает, что объект доступен по символьной ссылке и его поля
+	// инициализируются лениво. TypeName ограничивает возможный алиасинг
+	// объектами того же типа
+	Symbolic   bool
+	TypeName   string
+	FieldTypes []symbolic.ExpressionType
+	Initial    map[int]symbolic.SymbolicExpression // значения полей при ленивой инициализации
+
+	// owner — метка памяти, которой принадлежит объект. Чужие объекты
+	// перед изменением копируются
+	owner uint64
+}
+
+// ArrayWrite описывает одну запись в массив после появления символьных индексов
+type ArrayWrite struct {
+	Index symbolic.SymbolicExpression // nil, если индекс конкретный
+	Const int
+	Value symbolic.SymbolicExpression
 }
 
 func NewSymbolicMemory() *SymbolicMemory {
 	return &SymbolicMemory{
-		objects:      make(map[int]*MemoryObject),
 		nextObjectID: 1,
-		aliases:      make(map[int]int),
+		owner:        atomic.AddUint64(&lastOwner, 1),
 	}
 }
 
-func (sm *SymbolicMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref {
+// Fork создаёт копию памяти за O(1). После ветвления обе копии считают
+// общие объекты чужими и копируют их при записи
+func (sm *SymbolicMemory) Fork() Memory {
+	forked := *sm
+	forked.owner = atomic.AddUint64(&lastOwner, 1)
+	sm.owner = atomic.AddUint64(&lastOwner, 1)
+	return &forked
+}
+
+// object возвращает объект только для чтения
+func (sm *SymbolicMemory) object(id int) *MemoryObject {
+	obj, exists := sm.objects.get(id)
+	if !exists {
+		panic(fmt.Sprintf("Объект с ID %d не найден", id))
+	}
+	return obj
+}
+
+// mutableObject возвращает объект, который можно изменять, копируя его при необходимости
+func (sm *SymbolicMemory) mutableObject(id int) *MemoryObject {
+	obj := sm.object(id)
+	if obj.owner == sm.owner {
+		return obj
+	}
+
+	copied := &MemoryObject{
+		Type:       obj.Type,
+		Fields:     make(map[int]symbolic.SymbolicExpression, len(obj.Fields)),
+		Elems:      make(map[int]symbolic.SymbolicExpression, len(obj.Elems)),
+		Writes:     append([]ArrayWrite(nil), obj.Writes...),
+		ElemType:   obj.ElemType,
+		Symbolic:   obj.Symbolic,
+		TypeName:   obj.TypeName,
+		FieldTypes: obj.FieldTypes,
+		Initial:    make(map[int]symbolic.SymbolicExpression, len(obj.Initial)),
+		owner:      sm.owner,
+	}
+	for i, value := range obj.Fields {
+		copied.Fields[i] = value
+	}
+	for i, value := range obj.Initial {
+		copied.Initial[i] = value
+	}
+	for i, value := range obj.Elems {
+		copied.Elems[i] = value
+	}
+
+	sm.objects = sm.objects.set(id, copied)
+	return copied
+}
+
+// newObject добавляет в память новый объект и возвращает его ID
+func (sm *SymbolicMemory) newObject(obj *MemoryObject) int {
 	id := sm.nextObjectID
 	sm.nextObjectID++
 
-	sm.objects[id] = &MemoryObject{
+	obj.owner = sm.owner
+	sm.objects = sm.objects.set(id, obj)
+	return id
+}
+
+func (sm *SymbolicMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref {
+	id := sm.newObject(&MemoryObject{
 		Type:   tpe,
 		Fields: make(map[int]symbolic.SymbolicExpression),
 		Elems:  make(map[int]symbolic.SymbolicExpression),
-	}
+	})
 
 	return symbolic.NewRef(id, tpe)
 }
 
 func (sm *SymbolicMemory) getOriginalID(ref *symbolic.Ref) int {
-	if originalID, exists := sm.aliases[ref.ID]; exists {
+	if originalID, exists := sm.aliases.get(ref.ID); exists {
 		return originalID
 	}
 	return ref.ID
@@ -62,29 +173,35 @@ func (sm *SymbolicMemory) getOriginalID(ref *symbolic.Ref) int {
 
 func (sm *SymbolicMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) {
 	originalID := sm.getOriginalID(ref)
-	obj, exists := sm.objects[originalID]
-	if !exists {
-		panic(fmt.Sprintf("Объект с ID %d не найден", originalID))
-	}
+	obj := sm.mutableObject(originalID)
 
 	if obj.Type != symbolic.StructType {
 		panic("Попытка присвоить поле не-структуре")
 	}
 
+	if obj.Symbolic {
+		// Запись видна через любую ссылку, которая может совпадать с ref
+		for _, otherID := range sm.aliasCandidates(originalID) {
+			current := sm.symbolicField(otherID, fieldIdx)
+			sm.mutableObject(otherID).Fields[fieldIdx] = ite(sameAddress(originalID, otherID), value, current)
+		}
+	}
+
 	obj.Fields[fieldIdx] = value
 }
 
 func (sm *SymbolicMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
 	originalID := sm.getOriginalID(ref)
-	obj, exists := sm.objects[originalID]
-	if !exists {
-		panic(fmt.Sprintf("Объект с ID %d не найден", originalID))
-	}
+	obj := sm.object(originalID)
 
 	if obj.Type != symbolic.StructType {
 		panic("Попытка прочитать поле не-структуры")
 	}
 
+	if obj.Symbolic {
+		return sm.symbolicField(originalID, fieldIdx)
+	}
+
 	value, exists := obj.Fields[fieldIdx]
 	if !exists {
 		return symbolic.NewIntConstant(0)
@@ -93,43 +210,233 @@ func (sm *SymbolicMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int) symboli
 	return value
 }
 
-func (sm *SymbolicMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) {
-	originalID := sm.getOriginalID(ref)
-	obj, exists := sm.objects[originalID]
-	if !exists {
-		panic(fmt.Sprintf("Объект с ID %d не найден", originalID))
+// AllocateSymbolic создаёт объект, адрес которого неизвестен
+func (sm *SymbolicMemory) AllocateSymbolic(typeName string, fieldTypes []symbolic.ExpressionType) *symbolic.Ref {
+	id := sm.newObject(&MemoryObject{
+		Type:       symbolic.StructType,
+		Fields:     make(map[int]symbolic.SymbolicExpression),
+		Elems:      make(map[int]symbolic.SymbolicExpression),
+		Symbolic:   true,
+		TypeName:   typeName,
+		FieldTypes: fieldTypes,
+		Initial:    make(map[int]symbolic.SymbolicExpression),
+	})
+
+	return symbolic.NewSymbolicRef(id, symbolic.StructType)
+}
+
+// symbolicField возвращает значение поля символьного объекта, инициализируя его при первом чтении.
+// Если объект совпадает с другим символьным объектом, чьё поле уже известно,
+// то значение берётся оттуда, иначе — новая символьная переменная
+func (sm *SymbolicMemory) symbolicField(id int, fieldIdx int) symbolic.SymbolicExpression {
+	obj := sm.object(id)
+	if value, exists := obj.Fields[fieldIdx]; exists {
+		return value
 	}
 
-	if obj.Type != symbolic.ArrayType {
-		panic("Попытка присвоить элемент не-массиву")
+	fieldType := symbolic.IntType
+	if fieldIdx < len(obj.FieldTypes) {
+		fieldType = obj.FieldTypes[fieldIdx]
+	}
+
+	var value symbolic.SymbolicExpression = symbolic.NewSymbolicVariable(FieldVariableName(id, fieldIdx), fieldType)
+	for _, otherID := range sm.aliasCandidates(id) {
+		if known, exists := sm.object(otherID).Fields[fieldIdx]; exists {
+			value = ite(sameAddress(id, otherID), known, value)
+		}
 	}
 
+	obj = sm.mutableObject(id)
+	obj.Fields[fieldIdx] = value
+	obj.Initial[fieldIdx] = value
+	return value
+}
+
+// FieldVariableName возвращает имя переменной, которой лениво инициализируется
+// поле fieldIdx символьного объекта id
+func FieldVariableName(id int, fieldIdx int) string {
+	return fmt.Sprintf("ref_%d.%d", id, fieldIdx)
+}
+
+func (sm *SymbolicMemory) InitialFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
+	obj := sm.object(sm.getOriginalID(ref))
+	if !obj.Symbolic {
+		panic("Попытка прочитать начальное значение поля конкретного объекта")
+	}
+	return obj.Initial[fieldIdx]
+}
+
+// aliasCandidates возвращает отсортированные ID символьных объектов, которые могут совпадать с объектом id
+func (sm *SymbolicMemory) aliasCandidates(id int) []int {
+	obj := sm.object(id)
+	var candidates []int
+	sm.objects.forEach(func(otherID int, other *MemoryObject) {
+		if otherID != id && other.Symbolic && other.TypeName == obj.TypeName {
+			candidates = append(candidates, otherID)
+		}
+	})
+	return candidates
+}
+
+// sameAddress строит условие совпадения адресов двух символьных объектов
+func sameAddress(id, otherID int) symbolic.SymbolicExpression {
+	return symbolic.NewBinaryOperation(
+		symbolic.NewSymbolicRef(id, symbolic.StructType),
+		symbolic.NewSymbolicRef(otherID, symbolic.StructType),
+		symbolic.EQ,
+	)
+}
+
+func (sm *SymbolicMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) {
+	obj := sm.getArray(ref, "Попытка присвоить элемент не-массиву", true)
+
+	if len(obj.Writes) > 0 {
+		obj.Writes = append(obj.Writes, ArrayWrite{Const: index, Value: value})
+		return
+	}
 	obj.Elems[index] = value
 }
 
 func (sm *SymbolicMemory) GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression {
-	originalID := sm.getOriginalID(ref)
-	obj, exists := sm.objects[originalID]
+	obj := sm.getArray(ref, "Попытка прочитать элемент не-массива", false)
+
+	value, exists := obj.Elems[index]
 	if !exists {
-		panic(fmt.Sprintf("Объект с ID %d не найден", originalID))
+		value = symbolic.ZeroValue(obj.elemType())
 	}
 
-	if obj.Type != symbolic.ArrayType {
-		panic("Попытка прочитать элемент не-массива")
+	for _, write := range obj.Writes {
+		switch {
+		case write.Index == nil && write.Const == index:
+			value = write.Value
+		case write.Index != nil:
+			condition := symbolic.NewBinaryOperation(write.Index, indexConstant(index, write.Index.Type()), symbolic.EQ)
+			value = ite(condition, write.Value, value)
+		}
 	}
 
-	value, exists := obj.Elems[index]
-	if !exists {
-		return symbolic.NewIntConstant(0)
+	return value
+}
+
+// AssignToArraySymbolic записывает значение в массив по символьному индексу.
+// Предыдущие записи сохраняются и учитываются при последующих чтениях
+func (sm *SymbolicMemory) AssignToArraySymbolic(ref *symbolic.Ref, index symbolic.SymbolicExpression, value symbolic.SymbolicExpression) {
+	obj := sm.getArray(ref, "Попытка присвоить элемент не-массиву", true)
+
+	if constant, ok := concreteIndex(index); ok {
+		sm.AssignToArray(ref, constant, value)
+		return
+	}
+	obj.Writes = append(obj.Writes, ArrayWrite{Index: index, Value: value})
+}
+
+// GetFromArraySymbolic читает элемент массива по символьному индексу.
+// Результат — цепочка ITE по всем предыдущим записям, от последней к первой
+func (sm *SymbolicMemory) GetFromArraySymbolic(ref *symbolic.Ref, index symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	obj := sm.getArray(ref, "Попытка прочитать элемент не-массива", false)
+
+	if constant, ok := concreteIndex(index); ok {
+		return sm.GetFromArray(ref, constant)
+	}
+
+	var value symbolic.SymbolicExpression = symbolic.ZeroValue(obj.elemType())
+	for _, i := range sortedKeys(obj.Elems) {
+		condition := symbolic.NewBinaryOperation(index, indexConstant(i, index.Type()), symbolic.EQ)
+		value = ite(condition, obj.Elems[i], value)
+	}
+
+	for _, write := range obj.Writes {
+		var written symbolic.SymbolicExpression
+		if write.Index == nil {
+			written = indexConstant(write.Const, index.Type())
+		} else {
+			written = write.Index
+			if written.Type() != index.Type() {
+				written = symbolic.NewConversion(written, index.Type())
+			}
+		}
+		value = ite(symbolic.NewBinaryOperation(index, written, symbolic.EQ), write.Value, value)
 	}
 
 	return value
 }
 
+func (sm *SymbolicMemory) getArray(ref *symbolic.Ref, message string, mutable bool) *MemoryObject {
+	originalID := sm.getOriginalID(ref)
+	obj := sm.object(originalID)
+	if mutable && obj.Type == symbolic.ArrayType {
+		obj = sm.mutableObject(originalID)
+	}
+
+	if obj.Type != symbolic.ArrayType {
+		panic(message)
+	}
+	return obj
+}
+
+// elemType возвращает объявленный тип элементов массива, а если он не объявлен —
+// тип последнего записанного значения
+func (obj *MemoryObject) elemType() symbolic.ExpressionType {
+	if obj.ElemType != symbolic.IntType {
+		return obj.ElemType
+	}
+	if len(obj.Writes) > 0 {
+		return obj.Writes[len(obj.Writes)-1].Value.Type()
+	}
+	for _, i := range sortedKeys(obj.Elems) {
+		if elemType := obj.Elems[i].Type(); elemType != symbolic.IntType {
+			return elemType
+		}
+	}
+	return symbolic.IntType
+}
+
+// ite строит условное выражение, приводя целочисленные константы к типу другой ветви.
+// Это нужно, так как по умолчанию элементы инициализируются константой IntType
+func ite(condition, then, els symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if then.Type() != els.Type() {
+		if constant, ok := els.(*symbolic.IntConstant); ok && then.Type().IsBitVector() {
+			els = symbolic.NewBitVecConstant(constant.Value, then.Type())
+		} else if constant, ok := then.(*symbolic.IntConstant); ok && els.Type().IsBitVector() {
+			then = symbolic.NewBitVecConstant(constant.Value, els.Type())
+		}
+	}
+	return symbolic.NewIteExpression(condition, then, els)
+}
+
+// indexConstant создаёт константу индекса того же типа, что и символьный индекс
+func indexConstant(index int, exprType symbolic.ExpressionType) symbolic.SymbolicExpression {
+	if exprType.IsBitVector() {
+		return symbolic.NewBitVecConstant(int64(index), exprType)
+	}
+	return symbolic.NewIntConstant(int64(index))
+}
+
+// concreteIndex возвращает значение индекса, если он является константой
+func concreteIndex(index symbolic.SymbolicExpression) (int, bool) {
+	switch c := index.(type) {
+	case *symbolic.IntConstant:
+		return int(c.Value), true
+	case *symbolic.BitVecConstant:
+		return int(c.Value), true
+	default:
+		return 0, false
+	}
+}
+
+func sortedKeys(elems map[int]symbolic.SymbolicExpression) []int {
+	keys := make([]int, 0, len(elems))
+	for k := range elems {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
 // CreateAlias создаёт алиас для существующей ссылки
 func (sm *SymbolicMemory) CreateAlias(original *symbolic.Ref, aliasID int) *symbolic.Ref {
 	originalID := sm.getOriginalID(original)
-	sm.aliases[aliasID] = originalID
+	sm.aliases = sm.aliases.set(aliasID, originalID)
 	return symbolic.NewRef(aliasID, original.ExprType)
 }
 
@@ -137,8 +444,12 @@ func (sm *SymbolicMemory) CreateAlias(original *symbolic.Ref, aliasID int) *symb
 func (sm *SymbolicMemory) String() string {
 	result := "Symbolic Memory State:\n"
 
-	for id, obj := range sm.objects {
-		result += fmt.Sprintf("  Object %d (%s):\n", id, obj.Type.String())
+	sm.objects.forEach(func(id int, obj *MemoryObject) {
+		if obj.Symbolic {
+			result += fmt.Sprintf("  Object %d (%s, symbolic %s):\n", id, obj.Type.String(), obj.TypeName)
+		} else {
+			result += fmt.Sprintf("  Object %d (%s):\n", id, obj.Type.String())
+		}
 
 		switch obj.Type {
 		case symbolic.StructType:
@@ -149,24 +460,28 @@ func (sm *SymbolicMemory) String() string {
 			for index, elem := range obj.Elems {
 				result += fmt.Sprintf("    Elem[%d]: %s\n", index, elem.String())
 			}
+			for _, write := range obj.Writes {
+				if write.Index == nil {
+					result += fmt.Sprintf("    Write[%d]: %s\n", write.Const, write.Value.String())
+				} else {
+					result += fmt.Sprintf("    Write[%s]: %s\n", write.Index.String(), write.Value.String())
+				}
+			}
 		default:
 			result += fmt.Sprintf("    Simple type: %s\n", obj.Type.String())
 		}
-	}
+	})
 
 	result += "Aliases:\n"
-	for alias, original := range sm.aliases {
+	sm.aliases.forEach(func(alias int, original int) {
 		result += fmt.Sprintf("  %d -> %d\n", alias, original)
-	}
+	})
 
 	return result
 }
 
 // AllocateStruct создает структуру с заданным количеством полей
 func (sm *SymbolicMemory) AllocateStruct(fieldCount int) *symbolic.Ref {
-	id := sm.nextObjectID
-	sm.nextObjectID++
-
 	obj := &MemoryObject{
 		Type:   symbolic.StructType,
 		Fields: make(map[int]symbolic.SymbolicExpression),
@@ -177,25 +492,28 @@ func (sm *SymbolicMemory) AllocateStruct(fieldCount int) *symbolic.Ref {
 		obj.Fields[i] = symbolic.NewIntConstant(0)
 	}
 
-	sm.objects[id] = obj
+	id := sm.newObject(obj)
 	return symbolic.NewRef(id, symbolic.StructType)
 }
 
 // AllocateArray создает массив заданной длины
 func (sm *SymbolicMemory) AllocateArray(length int) *symbolic.Ref {
-	id := sm.nextObjectID
-	sm.nextObjectID++
+	return sm.AllocateTypedArray(length, symbolic.IntType)
+}
 
+// AllocateTypedArray создает массив заданной длины с элементами типа elemType
+func (sm *SymbolicMemory) AllocateTypedArray(length int, elemType symbolic.ExpressionType) *symbolic.Ref {
 	obj := &MemoryObject{
-		Type:   symbolic.ArrayType,
-		Fields: make(map[int]symbolic.SymbolicExpression),
-		Elems:  make(map[int]symbolic.SymbolicExpression),
+		Type:     symbolic.ArrayType,
+		Fields:   make(map[int]symbolic.SymbolicExpression),
+		Elems:    make(map[int]symbolic.SymbolicExpression),
+		ElemType: elemType,
 	}
 
 	for i := 0; i < length; i++ {
-		obj.Elems[i] = symbolic.NewIntConstant(0)
+		obj.Elems[i] = symbolic.ZeroValue(elemType)
 	}
 
-	sm.objects[id] = obj
+	id := sm.newObject(obj)
 	return symbolic.NewRef(id, symbolic.ArrayType)
 }
@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// ErrorKind — вид ошибки времени исполнения Go
+type ErrorKind int
+
+const (
+	DivisionByZero ErrorKind = iota
+	IndexOutOfRange
+	NilDereference
+	NegativeShift
+)
+
+// String возвращает сообщение, с которым паникует среда исполнения Go
+func (kind ErrorKind) String() string {
+	switch kind {
+	case DivisionByZero:
+		return "integer divide by zero"
+	case IndexOutOfRange:
+		return "index out of range"
+	case NilDereference:
+		return "nil pointer dereference"
+	case NegativeShift:
+		return "negative shift amount"
+	default:
+		return "unknown error"
+	}
+}
+
+// RuntimeError описывает достижимую ошибку времени исполнения.
+// State — состояние в момент ошибки, условие пути которого включает условие ошибки,
+// TestCase — входные данные из модели этого условия
+type RuntimeError struct {
+	Kind        ErrorKind
+	Position    token.Position
+	Instruction string
+	State       Interpreter
+	TestCase    TestCase
+}
+
+func (err RuntimeError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", err.Position, err.Kind, err.Instruction)
+}
+
+// checkRuntimeError сообщает об ошибке kind, если condition выполнимо на текущем пути,
+// и продолжает путь в предположении, что ошибки нет. Возвращает false,
+// если путь без ошибки невыполним
+func (interpreter *Interpreter) checkRuntimeError(instr ssa.Instruction, kind ErrorKind, condition symbolic.SymbolicExpression) bool {
+	condition = symbolic.Simplify(condition)
+	if value, ok := condition.(*symbolic.BoolConstant); ok {
+		if value.Value {
+			interpreter.Analyser.reportError(interpreter, instr, kind, interpreter.PathCondition)
+		}
+		return !value.Value
+	}
+
+	analyser := interpreter.Analyser
+	if errorCondition := conjunction(interpreter.PathCondition, condition); analyser.isSatisfiable(errorCondition) {
+		analyser.reportError(interpreter, instr, kind, errorCondition)
+	}
+
+	negation := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{condition}, symbolic.NOT)
+	safeCondition := conjunction(interpreter.PathCondition, negation)
+	if !analyser.isSatisfiable(safeCondition) {
+		return false
+	}
+	interpreter.PathCondition = safeCondition
+	return true
+}
+
+// reportError сохраняет ошибку kind в инструкции instr вместе с моделью условия pathCondition
+func (analyser *Analyser) reportError(interpreter *Interpreter, instr ssa.Instruction, kind ErrorKind, pathCondition *symbolic.PathConstraints) {
+	analyser.recordError(interpreter, RuntimeError{
+		Kind:        kind,
+		Position:    instr.Parent().Prog.Fset.Position(instr.Pos()),
+		Instruction: instr.String(),
+	}, pathCondition)
+}
+
+// recordError сохраняет ошибку failure, дополняя её состоянием и тестом по модели условия pathCondition
+func (analyser *Analyser) recordError(interpreter *Interpreter, failure RuntimeError, pathCondition *symbolic.PathConstraints) {
+	state := interpreter.fork()
+	state.PathCondition = pathCondition
+
+	testCase, ok := analyser.GenerateTestCase(state, fmt.Sprintf("error_%d", len(analyser.Errors)+1))
+	if !ok {
+		return
+	}
+	testCase.Expected = ""
+
+	failure.State = state
+	failure.TestCase = testCase
+	analyser.Errors = append(analyser.Errors, failure)
+}
+
+// checkRuntimeErrors проверяет, может ли инструкция element вызвать панику среды исполнения.
+// Возвращает false, если путь не может продолжиться без ошибки
+func (interpreter *Interpreter) checkRuntimeErrors(element ssa.Instruction) bool {
+	switch instr := element.(type) {
+	case *ssa.BinOp:
+		return interpreter.checkDivision(instr) && interpreter.checkShift(instr)
+	case *ssa.UnOp:
+		if instr.Op == token.MUL {
+			return interpreter.checkNil(instr, instr.X)
+		}
+	case *ssa.FieldAddr:
+		return interpreter.checkNil(instr, instr.X)
+	case *ssa.Store:
+		return interpreter.checkNil(instr, instr.Addr)
+	case *ssa.IndexAddr:
+		if _, ok := instr.X.Type().Underlying().(*types.Pointer); ok && !interpreter.checkNil(instr, instr.X) {
+			return false
+		}
+		return interpreter.checkIndex(instr, instr.X, instr.Index)
+	case *ssa.Index:
+		return interpreter.checkIndex(instr, instr.X, instr.Index)
+	}
+	return true
+}
+
+// checkDivision проверяет деление на ноль в целочисленных операциях / и %
+func (interpreter *Interpreter) checkDivision(instr *ssa.BinOp) bool {
+	if instr.Op != token.QUO && instr.Op != token.REM {
+		return true
+	}
+	if basic, ok := instr.Y.Type().Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+		return true
+	}
+
+	divisor := interpreter.resolveExpression(instr.Y)
+	zero := symbolic.ZeroValue(divisor.Type())
+	return interpreter.checkRuntimeError(instr, DivisionByZero, symbolic.NewBinaryOperation(divisor, zero, symbolic.EQ))
+}
+
+// checkShift проверяет, что знаковое число разрядов сдвига неотрицательно
+func (interpreter *Interpreter) checkShift(instr *ssa.BinOp) bool {
+	if instr.Op != token.SHL && instr.Op != token.SHR {
+		return true
+	}
+	if basic, ok := instr.Y.Type().Underlying().(*types.Basic); !ok || basic.Info()&types.IsUnsigned != 0 {
+		return true
+	}
+
+	count := interpreter.resolveExpression(instr.Y)
+	negative := symbolic.NewBinaryOperation(count, symbolic.ZeroValue(count.Type()), symbolic.LT)
+	return interpreter.checkRuntimeError(instr, NegativeShift, negative)
+}
+
+// checkIndex проверяет, что индекс лежит в полуинтервале [0, len(array))
+func (interpreter *Interpreter) checkIndex(instr ssa.Instruction, array, indexValue ssa.Value) bool {
+	index := interpreter.resolveIndex(indexValue)
+	length := interpreter.length(array)
+	zero := symbolic.NewBitVecConstant(0, indexType)
+	outOfRange := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(index, zero, symbolic.LT),
+		symbolic.NewBinaryOperation(index, length, symbolic.GE),
+	}, symbolic.OR)
+	return interpreter.checkRuntimeError(instr, IndexOutOfRange, outOfRange)
+}
+
+// checkNil проверяет, что разыменовываемый указатель value не nil.
+// Конкретный адрес известен заранее, а символьный адрес входного указателя может быть nil
+func (interpreter *Interpreter) checkNil(instr ssa.Instruction, value ssa.Value) bool {
+	address := interpreter.resolvePointer(value)
+	if !address.Base.Symbolic {
+		isNil := address.Base.ID == symbolic.NilRef().ID
+		return interpreter.checkRuntimeError(instr, NilDereference, symbolic.NewBoolConstant(isNil))
+	}
+
+	isNil := symbolic.NewBinaryOperation(address.Base, symbolic.NilRef(), symbolic.EQ)
+	return interpreter.checkRuntimeError(instr, NilDereference, isNil)
+}
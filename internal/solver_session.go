@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// SolverSession проверяет выполнимость условий путей одним инкрементальным решателем.
+// Каждый конъюнкт утверждается в собственной области видимости решателя,
+// поэтому при следующем запросе общий префикс конъюнктов остаётся в решателе,
+// а снимаются и добавляются только различающиеся конъюнкты.
+// При обходе в глубину запросы дочерних состояний отличаются от запроса
+// родителя одним-двумя конъюнктами
+type SolverSession struct {
+	translator *translator.Z3Translator
+	solver     *z3.Solver
+
+	// asserted[i] — конъюнкт, утверждённый в области видимости i+1
+	asserted []symbolic.SymbolicExpression
+	stats    SessionStats
+}
+
+// SessionStats — статистика сессии решателя
+type SessionStats struct {
+	Checks   int // число запросов
+	Asserted int // число утверждённых конъюнктов
+	Reused   int // число конъюнктов, оставшихся в решателе от предыдущих запросов
+	Popped   int // число снятых областей видимости
+}
+
+func NewSolverSession(z3Translator *translator.Z3Translator) *SolverSession {
+	return &SolverSession{
+		translator: z3Translator,
+		solver:     z3.NewSolver(z3Translator.GetContext().(*z3.Context)),
+	}
+}
+
+// Stats возвращает статистику сессии
+func (session *SolverSession) Stats() SessionStats {
+	return session.stats
+}
+
+// Check проверяет выполнимость конъюнкции conjuncts
+func (session *SolverSession) Check(conjuncts []symbolic.SymbolicExpression) (bool, error) {
+	session.stats.Checks++
+
+	common := 0
+	for common < len(conjuncts) && common < len(session.asserted) && symbolic.Equal(conjuncts[common], session.asserted[common]) {
+		common++
+	}
+
+	// Конъюнкты транслируются до изменения решателя, чтобы ошибка трансляции его не портила
+	added := make([]z3.Bool, 0, len(conjuncts)-common)
+	for _, conjunct := range conjuncts[common:] {
+		z3Conjunct, err := session.translator.TranslateExpression(conjunct)
+		if err != nil {
+			return false, err
+		}
+		added = append(added, z3Conjunct.(z3.Bool))
+	}
+
+	session.pop(len(session.asserted) - common)
+	session.stats.Reused += common
+	for i, z3Conjunct := range added {
+		session.solver.Push()
+		session.solver.Assert(z3Conjunct)
+		session.asserted = append(session.asserted, conjuncts[common+i])
+	}
+	session.stats.Asserted += len(added)
+
+	return session.solver.Check()
+}
+
+// Model возвращает модель последнего выполнимого запроса
+func (session *SolverSession) Model() *z3.Model {
+	return session.solver.Model()
+}
+
+// Reset снимает все утверждения
+func (session *SolverSession) Reset() {
+	session.pop(len(session.asserted))
+}
+
+func (session *SolverSession) pop(scopes int) {
+	for i := 0; i < scopes; i++ {
+		session.solver.Pop()
+	}
+	session.asserted = session.asserted[:len(session.asserted)-scopes]
+	session.stats.Popped += scopes
+}
@@ -2,6 +2,7 @@
 package ssa
 
 import (
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
@@ -57,6 +58,43 @@ func (b *Builder) ParseAndBuildSSA(source string, funcName string) (*ssa.Functio
 	return ssaPkg.Func(funcName), nil
 }
 
+// ParseAndBuildPackage парсит файлы одного пакета и создаёт его SSA представление.
+// Импорты других пакетов не поддерживаются
+func (b *Builder) ParseAndBuildPackage(filenames ...string) (*ssa.Package, error) {
+	var files []*ast.File
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(b.fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("нет файлов для анализа")
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+
+	config := &types.Config{}
+	pkg, err := config.Check(files[0].Name.Name, b.fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := ssa.NewProgram(b.fset, ssa.SanityCheckFunctions)
+	ssaPkg := prog.CreatePackage(pkg, files, info, false)
+	ssaPkg.Build()
+
+	return ssaPkg, nil
+}
+
 func (b *Builder) PrintFunctionInfo(fn *ssa.Function) {
 	if fn == nil {
 		println("Функция не найдена")
@@ -0,0 +1,144 @@
+package ssa
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Program — SSA программы, загруженной с диска вместе со всеми зависимостями
+type Program struct {
+	Program  *ssa.Program
+	Packages []*ssa.Package // пакеты, соответствующие шаблонам загрузки
+}
+
+// LoadPackages загружает пакеты по шаблонам patterns (как у go list) из модуля в каталоге dir
+// и строит SSA для них и всех их зависимостей из модулей. Стандартная библиотека
+// загружается без построения тел функций, её функции считаются внешними
+func (b *Builder) LoadPackages(dir string, patterns ...string) (*Program, error) {
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedTypesSizes | packages.NeedModule,
+		Dir:  dir,
+		Fset: b.fset,
+	}
+
+	initial, err := packages.Load(config, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("пакеты %v не найдены", patterns)
+	}
+
+	var errors []string
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			errors = append(errors, err.Error())
+		}
+	})
+	if len(errors) > 0 {
+		return nil, fmt.Errorf("ошибки загрузки пакетов:\n%s", strings.Join(errors, "\n"))
+	}
+
+	prog, pkgs := ssautil.AllPackages(initial, ssa.SanityCheckFunctions)
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		if pkg.Module != nil {
+			prog.Package(pkg.Types).Build()
+		}
+	})
+
+	return &Program{Program: prog, Packages: pkgs}, nil
+}
+
+// Function находит функцию или метод по полному имени: "pkg/path.Func",
+// "pkg/path.Type.Method" или "pkg/path.(*Type).Method"
+func (p *Program) Function(name string) *ssa.Function {
+	var pkg *ssa.Package
+	for _, candidate := range p.Program.AllPackages() {
+		path := candidate.Pkg.Path()
+		if strings.HasPrefix(name, path+".") && (pkg == nil || len(path) > len(pkg.Pkg.Path())) {
+			pkg = candidate
+		}
+	}
+	if pkg == nil {
+		return nil
+	}
+
+	member := strings.TrimPrefix(name, pkg.Pkg.Path()+".")
+	typeName, methodName, isMethod := strings.Cut(member, ".")
+	if !isMethod {
+		return pkg.Func(member)
+	}
+
+	typeName = strings.TrimSuffix(strings.TrimPrefix(typeName, "("), ")")
+	pointer := strings.HasPrefix(typeName, "*")
+	named := pkg.Type(strings.TrimPrefix(typeName, "*"))
+	if named == nil {
+		return nil
+	}
+
+	receivers := []types.Type{named.Type(), types.NewPointer(named.Type())}
+	if pointer {
+		receivers = receivers[1:]
+	}
+	for _, receiver := range receivers {
+		if selection := p.Program.MethodSets.MethodSet(receiver).Lookup(pkg.Pkg, methodName); selection != nil {
+			return p.Program.MethodValue(selection)
+		}
+	}
+	return nil
+}
+
+// Functions возвращает функции и методы загруженных пакетов, упорядоченные по полному имени
+func (p *Program) Functions() []*ssa.Function {
+	var functions []*ssa.Function
+	for _, pkg := range p.Packages {
+		for _, member := range pkg.Members {
+			switch m := member.(type) {
+			case *ssa.Function:
+				if m.Synthetic == "" {
+					functions = append(functions, m)
+				}
+			case *ssa.Type:
+				for _, receiver := range []types.Type{m.Type(), types.NewPointer(m.Type())} {
+					methods := p.Program.MethodSets.MethodSet(receiver)
+					for i := 0; i < methods.Len(); i++ {
+						method := p.Program.MethodValue(methods.At(i))
+						if method != nil && method.Synthetic == "" && method.Pkg == pkg {
+							functions = append(functions, method)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		return QualifiedName(functions[i]) < QualifiedName(functions[j])
+	})
+	return functions
+}
+
+// QualifiedName возвращает полное имя функции в формате, который принимает Program.Function
+func QualifiedName(function *ssa.Function) string {
+	if function.Pkg == nil {
+		return function.Name()
+	}
+	receiver := function.Signature.Recv()
+	if receiver == nil {
+		return function.Pkg.Pkg.Path() + "." + function.Name()
+	}
+
+	typeName := types.TypeString(receiver.Type(), types.RelativeTo(function.Pkg.Pkg))
+	if strings.HasPrefix(typeName, "*") {
+		typeName = "(" + typeName + ")"
+	}
+	return function.Pkg.Pkg.Path() + "." + typeName + "." + function.Name()
+}
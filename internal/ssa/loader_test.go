@@ -0,0 +1,99 @@
+package ssa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadPackages тестирует загрузку модуля с импортами и поиск функций по полному имени
+func TestLoadPackages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.21\n",
+		"shapes/shapes.go": `package shapes
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Rect struct {
+	Width, Height int
+}
+
+func (r Rect) Area() int {
+	return r.Width * r.Height
+}
+
+func (r *Rect) Scale(k int) {
+	r.Width *= k
+	r.Height *= k
+}
+
+func Validate(r Rect) error {
+	if r.Width < 0 {
+		return errors.New("negative width")
+	}
+	return fmt.Errorf("height %d", r.Height)
+}
+`,
+		"main.go": `package main
+
+import "example.com/demo/shapes"
+
+func Total(a, b shapes.Rect) int {
+	return a.Area() + b.Area()
+}
+
+func main() {}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	program, err := NewBuilder().LoadPackages(dir, "./...")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	for _, name := range []string{
+		"example.com/demo.Total",
+		"example.com/demo/shapes.Validate",
+		"example.com/demo/shapes.Rect.Area",
+		"example.com/demo/shapes.(*Rect).Scale",
+		"example.com/demo/shapes.Rect.Scale",
+	} {
+		function := program.Function(name)
+		if function == nil {
+			t.Errorf("Function %s not found", name)
+			continue
+		}
+		if len(function.Blocks) == 0 {
+			t.Errorf("Function %s has no SSA body", name)
+		}
+	}
+	if program.Function("fmt.Errorf") == nil {
+		t.Errorf("Expected standard library functions to be resolvable")
+	}
+	if program.Function("example.com/demo/shapes.Rect.Missing") != nil {
+		t.Errorf("Expected missing method not to be found")
+	}
+
+	names := map[string]bool{}
+	for _, function := range program.Functions() {
+		names[QualifiedName(function)] = true
+	}
+	for _, name := range []string{"example.com/demo/shapes.Rect.Area", "example.com/demo/shapes.(*Rect).Scale", "example.com/demo.main"} {
+		if !names[name] {
+			t.Errorf("Expected %s among loaded functions, got %v", name, names)
+		}
+	}
+}
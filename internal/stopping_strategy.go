@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	DefaultStepLimit = 10000
+	DefaultTimeLimit = 10 * time.Second
+)
+
+// StoppingStrategy определяет глобальный критерий остановки анализа
+type StoppingStrategy interface {
+	ShouldStop(analyser *Analyser) bool
+}
+
+// StepLimitStrategy останавливает анализ после Limit шагов интерпретации
+type StepLimitStrategy struct {
+	Limit int
+}
+
+func (strategy *StepLimitStrategy) ShouldStop(analyser *Analyser) bool {
+	return analyser.Steps >= strategy.Limit
+}
+
+// TimeLimitStrategy останавливает анализ по истечении Limit с момента запуска
+type TimeLimitStrategy struct {
+	Limit time.Duration
+}
+
+func (strategy *TimeLimitStrategy) ShouldStop(analyser *Analyser) bool {
+	return time.Since(analyser.StartTime) >= strategy.Limit
+}
+
+// StateLimitStrategy останавливает анализ, когда найдено Limit завершённых состояний
+type StateLimitStrategy struct {
+	Limit int
+}
+
+func (strategy *StateLimitStrategy) ShouldStop(analyser *Analyser) bool {
+	return len(analyser.Results) >= strategy.Limit
+}
+
+// CoverageStrategy останавливает анализ, когда покрыты все достижимые блоки анализируемой функции
+type CoverageStrategy struct {
+	reachable map[*ssa.BasicBlock]bool
+}
+
+func (strategy *CoverageStrategy) ShouldStop(analyser *Analyser) bool {
+	if strategy.reachable == nil {
+		strategy.reachable = reachableBlocks(analyser.Function)
+	}
+	for block := range strategy.reachable {
+		if !analyser.CoveredBlocks[block] {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyOfStrategy останавливает анализ, как только срабатывает любая из стратегий
+type AnyOfStrategy struct {
+	Strategies []StoppingStrategy
+}
+
+func (strategy *AnyOfStrategy) ShouldStop(analyser *Analyser) bool {
+	for _, s := range strategy.Strategies {
+		if s.ShouldStop(analyser) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultStoppingStrategy возвращает стратегию по умолчанию: ограничение на число шагов и время
+func DefaultStoppingStrategy() StoppingStrategy {
+	return &AnyOfStrategy{Strategies: []StoppingStrategy{
+		&StepLimitStrategy{Limit: DefaultStepLimit},
+		&TimeLimitStrategy{Limit: DefaultTimeLimit},
+	}}
+}
+
+func reachableBlocks(function *ssa.Function) map[*ssa.BasicBlock]bool {
+	reachable := make(map[*ssa.BasicBlock]bool)
+	if function == nil || len(function.Blocks) == 0 {
+		return reachable
+	}
+
+	worklist := []*ssa.BasicBlock{function.Blocks[0]}
+	for len(worklist) > 0 {
+		block := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		if reachable[block] {
+			continue
+		}
+		reachable[block] = true
+		worklist = append(worklist, block.Succs...)
+	}
+	return reachable
+}
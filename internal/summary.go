@@ -0,0 +1,327 @@
+package internal
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// DefaultSummaryStepLimit — число шагов, за которое должен завершиться анализ вызываемой функции.
+// Если исследовать все её пути не удалось, вызовы функции встраиваются
+const DefaultSummaryStepLimit = 1000
+
+// CallMode определяет, как исполняется вызов функции
+type CallMode int
+
+const (
+	// InlineCall исполняет тело вызываемой функции в новом фрейме
+	InlineCall CallMode = iota
+	// SummaryCall подставляет заранее вычисленную сводку функции
+	SummaryCall
+)
+
+// FunctionSummary описывает все пути функции через её параметры.
+// Params — символьные значения параметров, через которые выражены пути
+type FunctionSummary struct {
+	Function *ssa.Function
+	Params   []symbolic.SymbolicExpression
+	Paths    []SummaryPath
+}
+
+// SummaryPath — один путь функции: при выполнении Precondition функция возвращает Result
+// и записывает Effects в объекты, переданные по указателю, либо паникует, если Panicked,
+// либо завершается ошибкой времени исполнения Failure
+type SummaryPath struct {
+	Precondition symbolic.SymbolicExpression
+	Result       symbolic.SymbolicExpression
+	Effects      []FieldEffect
+	Panicked     bool
+	PanicValue   string
+	Failure      *RuntimeError
+}
+
+// FieldEffect — запись значения Value в поле Field объекта, на который указывает параметр Param
+type FieldEffect struct {
+	Param int
+	Field int
+	Value symbolic.SymbolicExpression
+}
+
+// SummaryCache хранит сводки функций. Для функций, которые нельзя описать сводкой, хранится nil
+type SummaryCache struct {
+	summaries map[*ssa.Function]*FunctionSummary
+	computing map[*ssa.Function]bool
+}
+
+func NewSummaryCache() *SummaryCache {
+	return &SummaryCache{
+		summaries: make(map[*ssa.Function]*FunctionSummary),
+		computing: make(map[*ssa.Function]bool),
+	}
+}
+
+// Get возвращает сводку функции, если она уже вычислена
+func (cache *SummaryCache) Get(function *ssa.Function) (*FunctionSummary, bool) {
+	summary, exists := cache.summaries[function]
+	return summary, exists
+}
+
+// callMode возвращает способ исполнения вызовов функции
+func (analyser *Analyser) callMode(function *ssa.Function) CallMode {
+	if mode, exists := analyser.CallModes[function]; exists {
+		return mode
+	}
+	return analyser.DefaultCallMode
+}
+
+// summary возвращает сводку функции, вычисляя её при первом обращении.
+// Для рекурсивных вызовов во время вычисления сводки и для неподдерживаемых функций возвращается nil
+func (analyser *Analyser) summary(function *ssa.Function) *FunctionSummary {
+	cache := analyser.Summaries
+	if summary, exists := cache.summaries[function]; exists {
+		return summary
+	}
+	if cache.computing[function] {
+		return nil
+	}
+
+	cache.computing[function] = true
+	summary := analyser.computeSummary(function)
+	delete(cache.computing, function)
+
+	cache.summaries[function] = summary
+	return summary
+}
+
+// computeSummary исследует все пути функции отдельным анализатором.
+// Если какой-то путь не исследован до конца, сводка была бы неполной, и функция встраивается
+func (analyser *Analyser) computeSummary(function *ssa.Function) *FunctionSummary {
+	if !summarizable(function.Signature) {
+		return nil
+	}
+
+	callee := NewAnalyser(&DfsPathSelector{}, &StepLimitStrategy{Limit: analyser.SummaryStepLimit})
+	callee.MaxCallDepth = analyser.MaxCallDepth
+	callee.SummaryStepLimit = analyser.SummaryStepLimit
+	callee.Summaries = analyser.Summaries
+	callee.Expressions = analyser.Expressions
+	callee.Strings = analyser.Strings
+	callee.CallModes = analyser.CallModes
+	callee.DefaultCallMode = analyser.DefaultCallMode
+
+	initial := NewInterpreter(callee, function)
+	summary := &FunctionSummary{Function: function}
+	for _, param := range function.Params {
+		summary.Params = append(summary.Params, initial.CallStack[0].LocalMemory[param.Name()])
+	}
+
+	callee.Package = function.Pkg
+	callee.Function = function
+	callee.run(initial)
+	if callee.StatesQueue.Len() > 0 || len(callee.Aborted) > 0 {
+		return nil
+	}
+
+	for _, failure := range callee.Errors {
+		summary.Paths = append(summary.Paths, SummaryPath{
+			Precondition: summaryPrecondition(failure.State, initial),
+			Failure:      &RuntimeError{Kind: failure.Kind, Position: failure.Position, Instruction: failure.Instruction},
+		})
+	}
+	for _, state := range callee.Panics {
+		summary.Paths = append(summary.Paths, SummaryPath{
+			Precondition: summaryPrecondition(state, initial),
+			Panicked:     true,
+			PanicValue:   state.PanicValue,
+		})
+	}
+	for _, state := range callee.Results {
+		path := SummaryPath{
+			Precondition: summaryPrecondition(state, initial),
+			Result:       state.CallStack[0].ReturnValue,
+		}
+		for i, param := range function.Params {
+			address, ok := summary.Params[i].(*pointer)
+			if !ok {
+				continue
+			}
+			for field := range flatFields(param.Type().Underlying().(*types.Pointer).Elem(), "") {
+				value := state.Heap.GetFieldValue(address.Base, field)
+				if variable, ok := value.(*symbolic.SymbolicVariable); ok && variable.Name == memory.FieldVariableName(address.Base.ID, field) {
+					continue
+				}
+				if value.Type() == symbolic.RefType {
+					// Ссылка на объект вызываемой функции не имеет смысла в вызывающей
+					return nil
+				}
+				path.Effects = append(path.Effects, FieldEffect{Param: i, Field: field, Value: value})
+			}
+		}
+		summary.Paths = append(summary.Paths, path)
+	}
+	return summary
+}
+
+// summaryPrecondition возвращает условие пути state без начальных ограничений параметров.
+// Они ссылаются на адреса символьных объектов вызываемой функции, а аргументы вызова
+// уже ограничены в вызывающем состоянии
+func summaryPrecondition(state, initial Interpreter) symbolic.SymbolicExpression {
+	constraints := state.PathCondition.Constraints()[initial.PathCondition.Len():]
+	switch len(constraints) {
+	case 0:
+		return symbolic.NewBoolConstant(true)
+	case 1:
+		return constraints[0]
+	default:
+		return symbolic.NewLogicalOperation(constraints, symbolic.AND)
+	}
+}
+
+// summarizable сообщает, можно ли выразить пути функции через её параметры:
+// параметры должны быть значениями базовых типов или указателями, а результат — не более
+// чем одним значением базового типа
+func summarizable(signature *types.Signature) bool {
+	for i := 0; i < signature.Params().Len(); i++ {
+		switch signature.Params().At(i).Type().Underlying().(type) {
+		case *types.Basic, *types.Pointer:
+		default:
+			return false
+		}
+	}
+
+	switch signature.Results().Len() {
+	case 0:
+		return true
+	case 1:
+		_, ok := signature.Results().At(0).Type().Underlying().(*types.Basic)
+		return ok
+	default:
+		return false
+	}
+}
+
+// applySummary исполняет вызов подстановкой сводки: каждый выполнимый путь
+// вызываемой функции порождает отдельное состояние
+func (interpreter *Interpreter) applySummary(instr *ssa.Call, summary *FunctionSummary) []Interpreter {
+	args := make([]symbolic.SymbolicExpression, len(instr.Call.Args))
+	for i, arg := range instr.Call.Args {
+		args[i] = interpreter.resolveExpression(arg)
+	}
+
+	var result []Interpreter
+	for _, path := range summary.Paths {
+		state := interpreter.fork()
+		instantiate := state.summaryInstantiation(summary, args)
+
+		precondition := symbolic.Simplify(instantiate(path.Precondition))
+		if value, ok := precondition.(*symbolic.BoolConstant); !ok || !value.Value {
+			pathCondition := conjunction(state.PathCondition, precondition)
+			if !state.Analyser.isSatisfiable(pathCondition) {
+				continue
+			}
+			state.PathCondition = pathCondition
+		}
+
+		if path.Failure != nil {
+			// Ошибка прерывает путь, а пути без неё описаны остальными путями сводки
+			state.Analyser.recordError(&state, *path.Failure, state.PathCondition)
+			continue
+		}
+		if path.Panicked {
+			state.Status = Panicked
+			state.PanicValue = path.PanicValue
+			result = append(result, state)
+			continue
+		}
+
+		// Все значения вычисляются до записи, так как выражены через состояние памяти до вызова
+		var returned []symbolic.SymbolicExpression
+		if path.Result != nil {
+			returned = append(returned, instantiate(path.Result))
+		}
+		effects := make([]symbolic.SymbolicExpression, len(path.Effects))
+		for i, effect := range path.Effects {
+			effects[i] = instantiate(effect.Value)
+		}
+
+		for i, effect := range path.Effects {
+			state.storeValue(args[effect.Param].(*pointer).offset(effect.Field), effects[i])
+		}
+		state.assignCallResults(instr, returned)
+		result = append(result, state)
+	}
+	return result
+}
+
+// summaryInstantiation возвращает функцию, выражающую значения из сводки через аргументы вызова.
+// Параметры заменяются аргументами, символьные объекты параметров — адресами из аргументов,
+// а начальные значения их полей — текущими значениями по этим адресам в памяти вызывающего состояния.
+// Аргументы могут указывать на разные поля одного объекта, поэтому условия совпадения
+// ссылок сводки заменяются сравнением адресов целиком
+func (interpreter *Interpreter) summaryInstantiation(summary *FunctionSummary, args []symbolic.SymbolicExpression) func(symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	variables := make(map[string]func() symbolic.SymbolicExpression)
+	addresses := make(map[int]*pointer)
+
+	for i, param := range summary.Params {
+		switch p := param.(type) {
+		case *symbolic.SymbolicVariable:
+			arg := args[i]
+			variables[p.Name] = func() symbolic.SymbolicExpression { return arg }
+		case *pointer:
+			address := args[i].(*pointer)
+			addresses[p.Base.ID] = address
+			fields := flatFields(summary.Function.Params[i].Type().Underlying().(*types.Pointer).Elem(), "")
+			for field := range fields {
+				var value symbolic.SymbolicExpression
+				variables[memory.FieldVariableName(p.Base.ID, field)] = func() symbolic.SymbolicExpression {
+					if value == nil {
+						value = interpreter.loadValue(address.offset(field))
+					}
+					return value
+				}
+			}
+		}
+	}
+
+	// address возвращает адрес вызывающего состояния, соответствующий ссылке из сводки
+	address := func(expr symbolic.SymbolicExpression) (*pointer, bool) {
+		ref, ok := expr.(*symbolic.Ref)
+		if !ok {
+			return nil, false
+		}
+		if !ref.Symbolic {
+			return &pointer{Base: ref}, true
+		}
+		address, exists := addresses[ref.ID]
+		return address, exists
+	}
+
+	replace := func(expr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		switch e := expr.(type) {
+		case *symbolic.SymbolicVariable:
+			if value, exists := variables[e.Name]; exists {
+				return value()
+			}
+		case *symbolic.BinaryOperation:
+			if e.Operator != symbolic.EQ {
+				return nil
+			}
+			left, leftOk := address(e.Left)
+			right, rightOk := address(e.Right)
+			if leftOk && rightOk {
+				return pointerEquality(left, right)
+			}
+		case *symbolic.Ref:
+			if address, exists := addresses[e.ID]; exists && e.Symbolic {
+				return address.Base
+			}
+		}
+		return nil
+	}
+
+	return func(expr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.Substitute(expr, replace)
+	}
+}
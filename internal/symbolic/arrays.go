@@ -0,0 +1,147 @@
+package symbolic
+
+import "fmt"
+
+// NewArrayVariable создаёт символьный массив с заданными сортами индекса и элемента
+func NewArrayVariable(name string, indexType, elemType ExpressionType) *SymbolicVariable {
+	return &SymbolicVariable{
+		Name:      name,
+		ExprType:  ArrayType,
+		IndexType: indexType,
+		ElemType:  elemType,
+	}
+}
+
+// ArraySorts возвращает сорта индекса и элемента выражения типа ArrayType
+func ArraySorts(expr SymbolicExpression) (indexType, elemType ExpressionType) {
+	switch e := expr.(type) {
+	case *SymbolicVariable:
+		return e.IndexType, e.ElemType
+	case *ArrayConstant:
+		return e.IndexType, e.ElemType
+	case *ArrayStore:
+		return e.IndexType, e.ElemType
+	case *IteExpression:
+		return ArraySorts(e.Then)
+	default:
+		panic(fmt.Sprintf("Выражение %s не является массивом", expr.String()))
+	}
+}
+
+// ArrayConstant представляет константный массив, все элементы которого равны Default
+type ArrayConstant struct {
+	IndexType ExpressionType
+	ElemType  ExpressionType
+	Default   SymbolicExpression
+
+	hash uint64 // запомненный хеш, см. Hash
+}
+
+// NewArrayConstant создаёт константный массив с индексами типа indexType
+func NewArrayConstant(indexType ExpressionType, value SymbolicExpression) *ArrayConstant {
+	return &ArrayConstant{
+		IndexType: indexType,
+		ElemType:  value.Type(),
+		Default:   value,
+	}
+}
+
+// Type возвращает тип выражения
+func (ac *ArrayConstant) Type() ExpressionType {
+	return ArrayType
+}
+
+// String возвращает строковое представление массива
+func (ac *ArrayConstant) String() string {
+	return fmt.Sprintf("const(%s)", ac.Default.String())
+}
+
+// Accept реализует Visitor pattern
+func (ac *ArrayConstant) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArrayConstant(ac)
+}
+
+// ArraySelect представляет чтение элемента массива по индексу
+type ArraySelect struct {
+	Array SymbolicExpression
+	Index SymbolicExpression
+
+	hash uint64 // запомненный хеш, см. Hash
+}
+
+// NewArraySelect создаёт чтение array[index]
+func NewArraySelect(array, index SymbolicExpression) *ArraySelect {
+	if array.Type() != ArrayType {
+		panic("Чтение по индексу требует массив")
+	}
+	if indexType, _ := ArraySorts(array); indexType != index.Type() {
+		panic("Тип индекса не совпадает с сортом индекса массива")
+	}
+	return &ArraySelect{
+		Array: array,
+		Index: index,
+	}
+}
+
+// Type возвращает тип элемента массива
+func (as *ArraySelect) Type() ExpressionType {
+	_, elemType := ArraySorts(as.Array)
+	return elemType
+}
+
+// String возвращает строковое представление чтения
+func (as *ArraySelect) String() string {
+	return fmt.Sprintf("%s[%s]", as.Array.String(), as.Index.String())
+}
+
+// Accept реализует Visitor pattern
+func (as *ArraySelect) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArraySelect(as)
+}
+
+// ArrayStore представляет массив, полученный из Array записью Value по индексу Index
+type ArrayStore struct {
+	Array     SymbolicExpression
+	Index     SymbolicExpression
+	Value     SymbolicExpression
+	IndexType ExpressionType
+	ElemType  ExpressionType
+
+	hash uint64 // запомненный хеш, см. Hash
+}
+
+// NewArrayStore создаёт запись array[index] = value
+func NewArrayStore(array, index, value SymbolicExpression) *ArrayStore {
+	if array.Type() != ArrayType {
+		panic("Запись по индексу требует массив")
+	}
+	indexType, elemType := ArraySorts(array)
+	if indexType != index.Type() {
+		panic("Тип индекса не совпадает с сортом индекса массива")
+	}
+	if elemType != value.Type() {
+		panic("Тип значения не совпадает с сортом элемента массива")
+	}
+	return &ArrayStore{
+		Array:     array,
+		Index:     index,
+		Value:     value,
+		IndexType: indexType,
+		ElemType:  elemType,
+	}
+}
+
+// Type возвращает тип выражения
+func (as *ArrayStore) Type() ExpressionType {
+	return ArrayType
+}
+
+// String возвращает строковое представление записи
+func (as *ArrayStore) String() string {
+	return fmt.Sprintf("%s{%s := %s}", as.Array.String(), as.Index.String(), as.Value.String())
+}
+
+// Accept реализует Visitor pattern
+func (as *ArrayStore) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArrayStore(as)
+}
@@ -7,6 +7,10 @@ type DebugVisitor struct {
 }
 
 func (dv *DebugVisitor) VisitVariable(expr *SymbolicVariable) interface{} {
+	if expr.Type() == ArrayType {
+		dv.printIndent("Variable: " + expr.Name + " (array[" + expr.IndexType.String() + "]" + expr.ElemType.String() + ")")
+		return nil
+	}
 	dv.printIndent("Variable: " + expr.Name + " (" + expr.Type().String() + ")")
 	return nil
 }
@@ -21,6 +25,16 @@ func (dv *DebugVisitor) VisitBoolConstant(expr *BoolConstant) interface{} {
 	return nil
 }
 
+func (dv *DebugVisitor) VisitBitVecConstant(expr *BitVecConstant) interface{} {
+	dv.printIndent("BitVecConstant: " + expr.String() + " (" + expr.Type().String() + ")")
+	return nil
+}
+
+func (dv *DebugVisitor) VisitFloatConstant(expr *FloatConstant) interface{} {
+	dv.printIndent("FloatConstant: " + expr.String() + " (" + expr.Type().String() + ")")
+	return nil
+}
+
 func (dv *DebugVisitor) VisitRef(expr *Ref) interface{} {
 	dv.printIndent("Ref: " + expr.String() + " (" + expr.Type().String() + ")")
 	return nil
@@ -54,6 +68,54 @@ func (dv *DebugVisitor) VisitUnaryOperation(expr *UnaryOperation) interface{} {
 	return nil
 }
 
+func (dv *DebugVisitor) VisitConversion(expr *Conversion) interface{} {
+	dv.printIndent("Conversion: " + expr.Type().String())
+	dv.Indent++
+	expr.Operand.Accept(dv)
+	dv.Indent--
+	return nil
+}
+
+func (dv *DebugVisitor) VisitIte(expr *IteExpression) interface{} {
+	dv.printIndent("Ite:")
+	dv.Indent++
+	dv.printIndent("Cond:")
+	expr.Cond.Accept(dv)
+	dv.printIndent("Then:")
+	expr.Then.Accept(dv)
+	dv.printIndent("Else:")
+	expr.Else.Accept(dv)
+	dv.Indent--
+	return nil
+}
+
+func (dv *DebugVisitor) VisitArrayConstant(expr *ArrayConstant) interface{} {
+	dv.printIndent("ArrayConstant: index " + expr.IndexType.String())
+	dv.Indent++
+	expr.Default.Accept(dv)
+	dv.Indent--
+	return nil
+}
+
+func (dv *DebugVisitor) VisitArraySelect(expr *ArraySelect) interface{} {
+	dv.printIndent("ArraySelect:")
+	dv.Indent++
+	expr.Array.Accept(dv)
+	expr.Index.Accept(dv)
+	dv.Indent--
+	return nil
+}
+
+func (dv *DebugVisitor) VisitArrayStore(expr *ArrayStore) interface{} {
+	dv.printIndent("ArrayStore:")
+	dv.Indent++
+	expr.Array.Accept(dv)
+	expr.Index.Accept(dv)
+	expr.Value.Accept(dv)
+	dv.Indent--
+	return nil
+}
+
 func (dv *DebugVisitor) printIndent(msg string) {
 	for i := 0; i < dv.Indent; i++ {
 		fmt.Print("  ")
@@ -0,0 +1,269 @@
+package symbolic
+
+import "math"
+
+// Структурное сравнение и хеширование выражений. Два выражения равны, если совпадают
+// их виды, типы, операторы, значения и рекурсивно все подвыражения.
+// Хеш структурно равных выражений совпадает; для составных узлов он вычисляется
+// один раз и запоминается, поэтому узлы после создания не должны изменяться
+
+const (
+	hashOffset uint64 = 14695981039346656037
+	hashPrime  uint64 = 1099511628211
+)
+
+// Виды узлов, различающие хеши выражений с одинаковыми полями
+const (
+	variableKind uint64 = iota + 1
+	intConstantKind
+	boolConstantKind
+	bitVecConstantKind
+	floatConstantKind
+	binaryOperationKind
+	logicalOperationKind
+	unaryOperationKind
+	iteKind
+	conversionKind
+	refKind
+	arrayConstantKind
+	arraySelectKind
+	arrayStoreKind
+)
+
+// HashCombine смешивает values в хеш seed
+func HashCombine(seed uint64, values ...uint64) uint64 {
+	for _, value := range values {
+		seed = (seed ^ value) * hashPrime
+		seed ^= seed >> 32
+	}
+	return seed
+}
+
+// HashString возвращает хеш строки по алгоритму FNV-1a
+func HashString(s string) uint64 {
+	hash := hashOffset
+	for i := 0; i < len(s); i++ {
+		hash = (hash ^ uint64(s[i])) * hashPrime
+	}
+	return hash
+}
+
+// HashOf возвращает хеш выражения, допускающего nil
+func HashOf(expr SymbolicExpression) uint64 {
+	if expr == nil {
+		return 0
+	}
+	return expr.Hash()
+}
+
+// Equal сравнивает выражения, допускающие nil
+func Equal(left, right SymbolicExpression) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	return left == right || left.Equals(right)
+}
+
+// cachedHash возвращает запомненный хеш или вычисляет его функцией compute.
+// Нулевой хеш не запоминается и вычисляется заново
+func cachedHash(cache *uint64, compute func() uint64) uint64 {
+	if *cache == 0 {
+		*cache = compute()
+	}
+	return *cache
+}
+
+// sameHashes быстро отвергает неравные выражения по несовпадающим хешам
+func sameHashes(left, right SymbolicExpression) bool {
+	return left.Hash() == right.Hash()
+}
+
+func (sv *SymbolicVariable) Hash() uint64 {
+	return HashCombine(variableKind, HashString(sv.Name), uint64(sv.ExprType), uint64(sv.IndexType), uint64(sv.ElemType))
+}
+
+func (sv *SymbolicVariable) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*SymbolicVariable)
+	return ok && (sv == o || sv.Name == o.Name && sv.ExprType == o.ExprType &&
+		sv.IndexType == o.IndexType && sv.ElemType == o.ElemType)
+}
+
+func (ic *IntConstant) Hash() uint64 {
+	return HashCombine(intConstantKind, uint64(ic.Value))
+}
+
+func (ic *IntConstant) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*IntConstant)
+	return ok && ic.Value == o.Value
+}
+
+func (bc *BoolConstant) Hash() uint64 {
+	if bc.Value {
+		return HashCombine(boolConstantKind, 1)
+	}
+	return HashCombine(boolConstantKind, 0)
+}
+
+func (bc *BoolConstant) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*BoolConstant)
+	return ok && bc.Value == o.Value
+}
+
+func (bc *BitVecConstant) Hash() uint64 {
+	return HashCombine(bitVecConstantKind, uint64(bc.Value), uint64(bc.ExprType))
+}
+
+func (bc *BitVecConstant) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*BitVecConstant)
+	return ok && bc.Value == o.Value && bc.ExprType == o.ExprType
+}
+
+// Hash константы с плавающей точкой учитывает битовое представление значения
+func (fc *FloatConstant) Hash() uint64 {
+	return HashCombine(floatConstantKind, math.Float64bits(fc.Value), uint64(fc.ExprType))
+}
+
+// Equals сравнивает битовые представления: NaN равен себе, а 0 и -0 различаются
+func (fc *FloatConstant) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*FloatConstant)
+	return ok && math.Float64bits(fc.Value) == math.Float64bits(o.Value) && fc.ExprType == o.ExprType
+}
+
+func (bo *BinaryOperation) Hash() uint64 {
+	return cachedHash(&bo.hash, func() uint64 {
+		return HashCombine(binaryOperationKind, uint64(bo.Operator), bo.Left.Hash(), bo.Right.Hash())
+	})
+}
+
+func (bo *BinaryOperation) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*BinaryOperation)
+	if !ok || bo == o {
+		return ok
+	}
+	return bo.Operator == o.Operator && sameHashes(bo, o) && Equal(bo.Left, o.Left) && Equal(bo.Right, o.Right)
+}
+
+func (lo *LogicalOperation) Hash() uint64 {
+	return cachedHash(&lo.hash, func() uint64 {
+		hash := HashCombine(logicalOperationKind, uint64(lo.Operator), uint64(len(lo.Operands)))
+		for _, operand := range lo.Operands {
+			hash = HashCombine(hash, operand.Hash())
+		}
+		return hash
+	})
+}
+
+func (lo *LogicalOperation) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*LogicalOperation)
+	if !ok || lo == o {
+		return ok
+	}
+	if lo.Operator != o.Operator || len(lo.Operands) != len(o.Operands) || !sameHashes(lo, o) {
+		return false
+	}
+	for i := range lo.Operands {
+		if !Equal(lo.Operands[i], o.Operands[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (uo *UnaryOperation) Hash() uint64 {
+	return cachedHash(&uo.hash, func() uint64 {
+		return HashCombine(unaryOperationKind, uint64(uo.Operator), uo.Operand.Hash())
+	})
+}
+
+func (uo *UnaryOperation) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*UnaryOperation)
+	if !ok || uo == o {
+		return ok
+	}
+	return uo.Operator == o.Operator && sameHashes(uo, o) && Equal(uo.Operand, o.Operand)
+}
+
+func (ite *IteExpression) Hash() uint64 {
+	return cachedHash(&ite.hash, func() uint64 {
+		return HashCombine(iteKind, ite.Cond.Hash(), ite.Then.Hash(), ite.Else.Hash())
+	})
+}
+
+func (ite *IteExpression) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*IteExpression)
+	if !ok || ite == o {
+		return ok
+	}
+	return sameHashes(ite, o) && Equal(ite.Cond, o.Cond) && Equal(ite.Then, o.Then) && Equal(ite.Else, o.Else)
+}
+
+func (c *Conversion) Hash() uint64 {
+	return cachedHash(&c.hash, func() uint64 {
+		return HashCombine(conversionKind, uint64(c.ExprType), c.Operand.Hash())
+	})
+}
+
+func (c *Conversion) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*Conversion)
+	if !ok || c == o {
+		return ok
+	}
+	return c.ExprType == o.ExprType && sameHashes(c, o) && Equal(c.Operand, o.Operand)
+}
+
+func (r *Ref) Hash() uint64 {
+	symbolic := uint64(0)
+	if r.Symbolic {
+		symbolic = 1
+	}
+	return HashCombine(refKind, uint64(r.ID), uint64(r.ExprType), symbolic)
+}
+
+func (r *Ref) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*Ref)
+	return ok && r.ID == o.ID && r.ExprType == o.ExprType && r.Symbolic == o.Symbolic
+}
+
+func (ac *ArrayConstant) Hash() uint64 {
+	return cachedHash(&ac.hash, func() uint64 {
+		return HashCombine(arrayConstantKind, uint64(ac.IndexType), uint64(ac.ElemType), ac.Default.Hash())
+	})
+}
+
+func (ac *ArrayConstant) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*ArrayConstant)
+	if !ok || ac == o {
+		return ok
+	}
+	return ac.IndexType == o.IndexType && ac.ElemType == o.ElemType && sameHashes(ac, o) && Equal(ac.Default, o.Default)
+}
+
+func (as *ArraySelect) Hash() uint64 {
+	return cachedHash(&as.hash, func() uint64 {
+		return HashCombine(arraySelectKind, as.Array.Hash(), as.Index.Hash())
+	})
+}
+
+func (as *ArraySelect) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*ArraySelect)
+	if !ok || as == o {
+		return ok
+	}
+	return sameHashes(as, o) && Equal(as.Array, o.Array) && Equal(as.Index, o.Index)
+}
+
+func (as *ArrayStore) Hash() uint64 {
+	return cachedHash(&as.hash, func() uint64 {
+		return HashCombine(arrayStoreKind, uint64(as.IndexType), uint64(as.ElemType),
+			as.Array.Hash(), as.Index.Hash(), as.Value.Hash())
+	})
+}
+
+func (as *ArrayStore) Equals(other SymbolicExpression) bool {
+	o, ok := other.(*ArrayStore)
+	if !ok || as == o {
+		return ok
+	}
+	return as.IndexType == o.IndexType && as.ElemType == o.ElemType && sameHashes(as, o) &&
+		Equal(as.Array, o.Array) && Equal(as.Index, o.Index) && Equal(as.Value, o.Value)
+}
@@ -1,7 +1,10 @@
 // Package symbolic содержит конкретные реализации символьных выражений
 package symbolic
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 // Операторы для бинарных выражений
 type BinaryOperator int
@@ -21,6 +24,14 @@ const (
 	LE // меньше или равно
 	GT // больше
 	GE // больше или равно
+
+	// Побитовые операторы и сдвиги
+	BIT_AND     // &
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND_NOT // &^
+	SHL         // <<
+	SHR         // >>
 )
 
 func (op BinaryOperator) String() string {
@@ -47,6 +58,18 @@ func (op BinaryOperator) String() string {
 		return ">"
 	case GE:
 		return ">="
+	case BIT_AND:
+		return "&"
+	case BIT_OR:
+		return "|"
+	case BIT_XOR:
+		return "^"
+	case BIT_AND_NOT:
+		return "&^"
+	case SHL:
+		return "<<"
+	case SHR:
+		return ">>"
 	default:
 		return "unknown"
 	}
@@ -79,8 +102,9 @@ func (op LogicalOperator) String() string {
 type UnaryOperator int
 
 const (
-	UNARY_MINUS UnaryOperator = iota // -x
-	UNARY_NOT                        // !x или not x
+	UNARY_MINUS      UnaryOperator = iota // -x
+	UNARY_NOT                             // !x или not x
+	UNARY_COMPLEMENT                      // ^x, побитовое дополнение
 )
 
 func (op UnaryOperator) String() string {
@@ -89,6 +113,8 @@ func (op UnaryOperator) String() string {
 		return "-"
 	case UNARY_NOT:
 		return "!"
+	case UNARY_COMPLEMENT:
+		return "^"
 	default:
 		return "unknown"
 	}
@@ -104,12 +130,22 @@ type SymbolicExpression interface {
 
 	// Accept принимает visitor для обхода дерева выражений
 	Accept(visitor Visitor) interface{}
+
+	// Equals сообщает, равно ли выражение структурно выражению other
+	Equals(other SymbolicExpression) bool
+
+	// Hash возвращает хеш, совпадающий у структурно равных выражений
+	Hash() uint64
 }
 
 // SymbolicVariable представляет символьную переменную
 type SymbolicVariable struct {
 	Name     string
 	ExprType ExpressionType
+
+	// Сорта индекса и элемента для переменных типа ArrayType
+	IndexType ExpressionType
+	ElemType  ExpressionType
 }
 
 // NewSymbolicVariable создаёт новую символьную переменную
@@ -185,27 +221,141 @@ func (bc *BoolConstant) Accept(visitor Visitor) interface{} {
 	return visitor.VisitBoolConstant(bc)
 }
 
+// BitVecConstant представляет целочисленную константу фиксированной ширины
+type BitVecConstant struct {
+	Value    int64 // значение, приведённое к ширине типа (для беззнаковых — битовое представление)
+	ExprType ExpressionType
+}
+
+// NewBitVecConstant создаёт константу типа exprType, усекая value по ширине типа
+// так же, как это делает преобразование типов в Go
+func NewBitVecConstant(value int64, exprType ExpressionType) *BitVecConstant {
+	if !exprType.IsBitVector() {
+		panic("Константа битового вектора требует целый тип фиксированной ширины")
+	}
+	return &BitVecConstant{Value: truncate(value, exprType), ExprType: exprType}
+}
+
+// truncate приводит значение к ширине целого типа с учётом знаковости
+func truncate(value int64, exprType ExpressionType) int64 {
+	width := exprType.BitWidth()
+	if width == 64 {
+		return value
+	}
+	shift := uint(64 - width)
+	if exprType.IsSigned() {
+		return (value << shift) >> shift
+	}
+	return int64(uint64(value<<shift) >> shift)
+}
+
+// Type возвращает тип константы
+func (bc *BitVecConstant) Type() ExpressionType {
+	return bc.ExprType
+}
+
+// String возвращает строковое представление константы
+func (bc *BitVecConstant) String() string {
+	if bc.ExprType.IsSigned() {
+		return fmt.Sprintf("%d", bc.Value)
+	}
+	return fmt.Sprintf("%d", uint64(bc.Value))
+}
+
+// Accept реализует Visitor pattern
+func (bc *BitVecConstant) Accept(visitor Visitor) interface{} {
+	return visitor.VisitBitVecConstant(bc)
+}
+
+// ZeroValue возвращает нулевое значение типа exprType
+func ZeroValue(exprType ExpressionType) SymbolicExpression {
+	switch {
+	case exprType == BoolType:
+		return NewBoolConstant(false)
+	case exprType.IsBitVector():
+		return NewBitVecConstant(0, exprType)
+	case exprType.IsFloat():
+		return NewFloatConstant(0, exprType)
+	case exprType == RefType:
+		return NilRef()
+	default:
+		return NewIntConstant(0)
+	}
+}
+
+// FloatConstant представляет константу с плавающей точкой
+type FloatConstant struct {
+	Value    float64
+	ExprType ExpressionType
+}
+
+// NewFloatConstant создаёт константу типа exprType.
+// Для float32 значение округляется до одинарной точности, как при преобразовании в Go
+func NewFloatConstant(value float64, exprType ExpressionType) *FloatConstant {
+	if !exprType.IsFloat() {
+		panic("Константа с плавающей точкой требует тип float32 или float64")
+	}
+	if exprType == Float32Type {
+		value = float64(float32(value))
+	}
+	return &FloatConstant{Value: value, ExprType: exprType}
+}
+
+// Type возвращает тип константы
+func (fc *FloatConstant) Type() ExpressionType {
+	return fc.ExprType
+}
+
+// String возвращает строковое представление константы
+func (fc *FloatConstant) String() string {
+	bitSize := 64
+	if fc.ExprType == Float32Type {
+		bitSize = 32
+	}
+	return strconv.FormatFloat(fc.Value, 'g', -1, bitSize)
+}
+
+// Accept реализует Visitor pattern
+func (fc *FloatConstant) Accept(visitor Visitor) interface{} {
+	return visitor.VisitFloatConstant(fc)
+}
+
 // BinaryOperation представляет бинарную операцию
 type BinaryOperation struct {
 	Left     SymbolicExpression
 	Right    SymbolicExpression
 	Operator BinaryOperator
+
+	hash uint64 // запомненный хеш, см. Hash
 }
 
 // NewBinaryOperation создаёт новую бинарную операцию
 func NewBinaryOperation(left, right SymbolicExpression, op BinaryOperator) *BinaryOperation {
 	switch op {
-	case ADD, SUB, MUL, DIV, MOD:
-		if left.Type() != IntType || right.Type() != IntType {
-			panic("Арифметические операции требуют целочисленные операнды")
+	case ADD, SUB, MUL, DIV:
+		if !left.Type().IsNumeric() || left.Type() != right.Type() {
+			panic("Арифметические операции требуют числовые операнды одного типа")
+		}
+	case MOD:
+		if !left.Type().IsInteger() || left.Type() != right.Type() {
+			panic("Остаток от деления требует целочисленные операнды одного типа")
 		}
 	case EQ, NE:
 		if left.Type() != right.Type() {
 			panic("Операторы сравнения требуют операнды одного типа")
 		}
 	case LT, LE, GT, GE:
-		if left.Type() != IntType || right.Type() != IntType {
-			panic("Операторы сравнения требуют целочисленные операнды")
+		if !left.Type().IsNumeric() || left.Type() != right.Type() {
+			panic("Операторы сравнения требуют числовые операнды одного типа")
+		}
+	case BIT_AND, BIT_OR, BIT_XOR, BIT_AND_NOT:
+		if !left.Type().IsInteger() || left.Type() != right.Type() {
+			panic("Побитовые операции требуют целочисленные операнды одного типа")
+		}
+	case SHL, SHR:
+		// Как и в Go, величина сдвига может иметь любой целый тип
+		if !left.Type().IsInteger() || !right.Type().IsInteger() {
+			panic("Сдвиги требуют целочисленные операнды")
 		}
 	}
 
@@ -219,8 +369,8 @@ func NewBinaryOperation(left, right SymbolicExpression, op BinaryOperator) *Bina
 // Type возвращает результирующий тип операции
 func (bo *BinaryOperation) Type() ExpressionType {
 	switch bo.Operator {
-	case ADD, SUB, MUL, DIV, MOD:
-		return IntType
+	case ADD, SUB, MUL, DIV, MOD, BIT_AND, BIT_OR, BIT_XOR, BIT_AND_NOT, SHL, SHR:
+		return bo.Left.Type()
 	case EQ, NE, LT, LE, GT, GE:
 		return BoolType
 	default:
@@ -242,6 +392,8 @@ func (bo *BinaryOperation) Accept(visitor Visitor) interface{} {
 type LogicalOperation struct {
 	Operands []SymbolicExpression
 	Operator LogicalOperator
+
+	hash uint64 // запомненный хеш, см. Hash
 }
 
 // NewLogicalOperation создаёт новую логическую операцию
@@ -307,19 +459,25 @@ func (lo *LogicalOperation) Accept(visitor Visitor) interface{} {
 type UnaryOperation struct {
 	Operand  SymbolicExpression
 	Operator UnaryOperator
+
+	hash uint64 // запомненный хеш, см. Hash
 }
 
 // NewUnaryOperation создаёт новую унарную операцию
 func NewUnaryOperation(operand SymbolicExpression, op UnaryOperator) *UnaryOperation {
 	switch op {
 	case UNARY_MINUS:
-		if operand.Type() != IntType {
-			panic("Унарный минус требует целочисленный операнд")
+		if !operand.Type().IsNumeric() {
+			panic("Унарный минус требует числовой операнд")
 		}
 	case UNARY_NOT:
 		if operand.Type() != BoolType {
 			panic("Логическое НЕ требует булев операнд")
 		}
+	case UNARY_COMPLEMENT:
+		if !operand.Type().IsInteger() {
+			panic("Побитовое дополнение требует целочисленный операнд")
+		}
 	}
 
 	return &UnaryOperation{
@@ -343,9 +501,87 @@ func (uo *UnaryOperation) Accept(visitor Visitor) interface{} {
 	return visitor.VisitUnaryOperation(uo)
 }
 
+// IteExpression представляет условное выражение: Then, если Cond истинно, иначе Else
+type IteExpression struct {
+	Cond SymbolicExpression
+	Then SymbolicExpression
+	Else SymbolicExpression
+
+	hash uint64 // запомненный хеш, см. Hash
+}
+
+// NewIteExpression создаёт условное выражение
+func NewIteExpression(cond, then, els SymbolicExpression) *IteExpression {
+	if cond.Type() != BoolType {
+		panic("Условие ITE должно быть булевым")
+	}
+	if then.Type() != els.Type() {
+		panic("Ветви ITE должны иметь один тип")
+	}
+	return &IteExpression{
+		Cond: cond,
+		Then: then,
+		Else: els,
+	}
+}
+
+// Type возвращает тип ветвей выражения
+func (ite *IteExpression) Type() ExpressionType {
+	return ite.Then.Type()
+}
+
+// String возвращает строковое представление выражения
+func (ite *IteExpression) String() string {
+	return fmt.Sprintf("ite(%s, %s, %s)", ite.Cond.String(), ite.Then.String(), ite.Else.String())
+}
+
+// Accept реализует Visitor pattern
+func (ite *IteExpression) Accept(visitor Visitor) interface{} {
+	return visitor.VisitIte(ite)
+}
+
+// Conversion представляет преобразование числового значения к другому числовому типу
+// по правилам Go: усечение или расширение целых, округление для чисел с плавающей точкой
+type Conversion struct {
+	Operand  SymbolicExpression
+	ExprType ExpressionType
+
+	hash uint64 // запомненный хеш, см. Hash
+}
+
+// NewConversion создаёт преобразование operand к типу exprType
+func NewConversion(operand SymbolicExpression, exprType ExpressionType) *Conversion {
+	if !operand.Type().IsNumeric() || !exprType.IsNumeric() {
+		panic("Преобразование поддерживается только между числовыми типами")
+	}
+	return &Conversion{
+		Operand:  operand,
+		ExprType: exprType,
+	}
+}
+
+// Type возвращает целевой тип преобразования
+func (c *Conversion) Type() ExpressionType {
+	return c.ExprType
+}
+
+// String возвращает строковое представление преобразования
+func (c *Conversion) String() string {
+	return fmt.Sprintf("%s(%s)", c.ExprType.String(), c.Operand.String())
+}
+
+// Accept реализует Visitor pattern
+func (c *Conversion) Accept(visitor Visitor) interface{} {
+	return visitor.VisitConversion(c)
+}
+
 type Ref struct {
 	ID       int
 	ExprType ExpressionType
+
+	// Symbolic означает, что адрес ссылки неизвестен и определяется решателем:
+	// такая ссылка может совпадать с другими символьными ссылками или быть nil
+	Symbolic bool
 }
 
 func NewRef(id int, exprType ExpressionType) *Ref {
@@ -355,6 +591,20 @@ func NewRef(id int, exprType ExpressionType) *Ref {
 	}
 }
 
+// NewSymbolicRef создаёт ссылку с символьным адресом
+func NewSymbolicRef(id int, exprType ExpressionType) *Ref {
+	return &Ref{
+		ID:       id,
+		ExprType: exprType,
+		Symbolic: true,
+	}
+}
+
+// NilRef возвращает нулевую ссылку
+func NilRef() *Ref {
+	return NewRef(0, RefType)
+}
+
 func (r *Ref) Type() ExpressionType {
 	return RefType
 }
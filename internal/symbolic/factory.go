@@ -0,0 +1,97 @@
+package symbolic
+
+// ExpressionFactory интернирует выражения: структурно равные выражения, прошедшие
+// через одну фабрику, представлены одним узлом. Для интернированных выражений
+// структурное равенство совпадает с равенством указателей, а общие подвыражения
+// хранятся в памяти один раз
+type ExpressionFactory struct {
+	table    map[uint64][]SymbolicExpression
+	interned map[SymbolicExpression]bool
+}
+
+func NewExpressionFactory() *ExpressionFactory {
+	return &ExpressionFactory{
+		table:    make(map[uint64][]SymbolicExpression),
+		interned: make(map[SymbolicExpression]bool),
+	}
+}
+
+// Intern возвращает канонический узел, структурно равный expr.
+// Подвыражения интернируются рекурсивно; сам expr не изменяется
+func (factory *ExpressionFactory) Intern(expr SymbolicExpression) SymbolicExpression {
+	if expr == nil || factory.interned[expr] {
+		return expr
+	}
+
+	expr = factory.internChildren(expr)
+	hash := expr.Hash()
+	for _, candidate := range factory.table[hash] {
+		if candidate.Equals(expr) {
+			return candidate
+		}
+	}
+	factory.table[hash] = append(factory.table[hash], expr)
+	factory.interned[expr] = true
+	return expr
+}
+
+// Size возвращает число различных интернированных выражений
+func (factory *ExpressionFactory) Size() int {
+	return len(factory.interned)
+}
+
+// internChildren возвращает узел expr с интернированными подвыражениями.
+// Если подвыражения уже канонические, возвращается сам expr, иначе — его копия.
+// Узлы, о которых фабрика не знает, интернируются целиком как листья
+func (factory *ExpressionFactory) internChildren(expr SymbolicExpression) SymbolicExpression {
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		left, right := factory.Intern(e.Left), factory.Intern(e.Right)
+		if left != e.Left || right != e.Right {
+			return &BinaryOperation{Left: left, Right: right, Operator: e.Operator}
+		}
+	case *LogicalOperation:
+		operands, changed := factory.internAll(e.Operands)
+		if changed {
+			return &LogicalOperation{Operands: operands, Operator: e.Operator}
+		}
+	case *UnaryOperation:
+		if operand := factory.Intern(e.Operand); operand != e.Operand {
+			return &UnaryOperation{Operand: operand, Operator: e.Operator}
+		}
+	case *IteExpression:
+		cond, then, els := factory.Intern(e.Cond), factory.Intern(e.Then), factory.Intern(e.Else)
+		if cond != e.Cond || then != e.Then || els != e.Else {
+			return &IteExpression{Cond: cond, Then: then, Else: els}
+		}
+	case *Conversion:
+		if operand := factory.Intern(e.Operand); operand != e.Operand {
+			return &Conversion{Operand: operand, ExprType: e.ExprType}
+		}
+	case *ArrayConstant:
+		if value := factory.Intern(e.Default); value != e.Default {
+			return &ArrayConstant{IndexType: e.IndexType, ElemType: e.ElemType, Default: value}
+		}
+	case *ArraySelect:
+		array, index := factory.Intern(e.Array), factory.Intern(e.Index)
+		if array != e.Array || index != e.Index {
+			return &ArraySelect{Array: array, Index: index}
+		}
+	case *ArrayStore:
+		array, index, value := factory.Intern(e.Array), factory.Intern(e.Index), factory.Intern(e.Value)
+		if array != e.Array || index != e.Index || value != e.Value {
+			return &ArrayStore{Array: array, Index: index, Value: value, IndexType: e.IndexType, ElemType: e.ElemType}
+		}
+	}
+	return expr
+}
+
+func (factory *ExpressionFactory) internAll(exprs []SymbolicExpression) ([]SymbolicExpression, bool) {
+	result := make([]SymbolicExpression, len(exprs))
+	changed := false
+	for i, expr := range exprs {
+		result[i] = factory.Intern(expr)
+		changed = changed || result[i] != expr
+	}
+	return result, changed
+}
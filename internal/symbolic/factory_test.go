@@ -0,0 +1,74 @@
+package symbolic
+
+import "testing"
+
+// TestEquals тестирует структурное равенство и согласованность хешей
+func TestEquals(t *testing.T) {
+	build := func() SymbolicExpression {
+		x := NewSymbolicVariable("x", Int64Type)
+		sum := NewBinaryOperation(x, NewBitVecConstant(1, Int64Type), ADD)
+		return NewLogicalOperation([]SymbolicExpression{
+			NewBinaryOperation(sum, NewBitVecConstant(10, Int64Type), LT),
+			NewLogicalOperation([]SymbolicExpression{NewBinaryOperation(x, sum, EQ)}, NOT),
+		}, AND)
+	}
+
+	tests := []struct {
+		name  string
+		left  SymbolicExpression
+		right SymbolicExpression
+		equal bool
+	}{
+		{"same tree", build(), build(), true},
+		{"variable type", NewSymbolicVariable("x", Int64Type), NewSymbolicVariable("x", Int32Type), false},
+		{"constant type", NewBitVecConstant(5, Int64Type), NewBitVecConstant(5, Int32Type), false},
+		{"int and bitvec", NewIntConstant(5), NewBitVecConstant(5, Int64Type), false},
+		{"float", NewFloatConstant(0.5, Float64Type), NewFloatConstant(0.5, Float32Type), false},
+		{"operator", NewBinaryOperation(NewIntConstant(1), NewIntConstant(2), ADD), NewBinaryOperation(NewIntConstant(1), NewIntConstant(2), SUB), false},
+		{"operand order", NewBinaryOperation(NewIntConstant(1), NewIntConstant(2), ADD), NewBinaryOperation(NewIntConstant(2), NewIntConstant(1), ADD), false},
+		{"symbolic ref", NewRef(1, RefType), NewSymbolicRef(1, RefType), false},
+		{"array", NewArrayConstant(Int64Type, NewBoolConstant(false)), NewArrayConstant(Int64Type, NewBoolConstant(false)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.left.Equals(tt.right) != tt.equal || tt.right.Equals(tt.left) != tt.equal {
+				t.Errorf("Equals(%s, %s) != %t", tt.left, tt.right, tt.equal)
+			}
+			if tt.equal && tt.left.Hash() != tt.right.Hash() {
+				t.Errorf("Equal expressions %s have different hashes", tt.left)
+			}
+		})
+	}
+}
+
+// TestExpressionFactory тестирует, что интернированные равные выражения совпадают по указателю
+func TestExpressionFactory(t *testing.T) {
+	factory := NewExpressionFactory()
+	x := NewSymbolicVariable("x", IntType)
+	square := func() SymbolicExpression {
+		return NewBinaryOperation(NewSymbolicVariable("x", IntType), NewSymbolicVariable("x", IntType), MUL)
+	}
+
+	first := factory.Intern(NewBinaryOperation(square(), NewIntConstant(4), GT))
+	second := factory.Intern(NewBinaryOperation(square(), NewIntConstant(4), GT))
+	if first != second {
+		t.Errorf("Expected equal expressions to be interned to one node")
+	}
+
+	other := factory.Intern(NewBinaryOperation(square(), NewIntConstant(4), LT))
+	if other == first {
+		t.Errorf("Expected different expressions to stay different")
+	}
+	if first.(*BinaryOperation).Left != other.(*BinaryOperation).Left {
+		t.Errorf("Expected common subexpression to be shared")
+	}
+	if factory.Intern(x) != first.(*BinaryOperation).Left.(*BinaryOperation).Left {
+		t.Errorf("Expected variable to be interned once")
+	}
+
+	// x, x * x, 4, x * x > 4, x * x < 4
+	if factory.Size() != 5 {
+		t.Errorf("Expected 5 interned expressions, got %d", factory.Size())
+	}
+}
@@ -0,0 +1,83 @@
+package symbolic
+
+import "math/bits"
+
+const (
+	hashTrieBits = 5
+	hashTrieMask = 1<<hashTrieBits - 1
+)
+
+// hashTrie — неизменяемое отображение 64-битных хэшей в списки значений,
+// устроенное как префиксное дерево по 5 битам хэша. Узел хранит только занятые
+// ячейки, отмеченные в bitmap. Запись копирует путь от корня до ячейки,
+// поэтому стоит O(log n), а версии отображения разделяют неизменённые поддеревья.
+// Пустое отображение представлено nil
+type hashTrie[V any] struct {
+	bitmap uint32
+	slots  []*hashSlot[V]
+}
+
+// hashSlot — ячейка узла: поддерево child либо значения values с хэшем hash
+type hashSlot[V any] struct {
+	child  *hashTrie[V]
+	hash   uint64
+	values []V
+}
+
+// lookup возвращает значения с хэшем hash
+func (trie *hashTrie[V]) lookup(hash uint64) []V {
+	for shift := uint(0); trie != nil; shift += hashTrieBits {
+		bit := uint32(1) << (hash >> shift & hashTrieMask)
+		if trie.bitmap&bit == 0 {
+			return nil
+		}
+		slot := trie.slots[bits.OnesCount32(trie.bitmap&(bit-1))]
+		if slot.child == nil {
+			if slot.hash == hash {
+				return slot.values
+			}
+			return nil
+		}
+		trie = slot.child
+	}
+	return nil
+}
+
+// with возвращает отображение, в котором хэшу hash соответствуют values.
+// Исходное отображение не изменяется
+func (trie *hashTrie[V]) with(hash uint64, values []V) *hashTrie[V] {
+	return trie.set(0, &hashSlot[V]{hash: hash, values: values})
+}
+
+func (trie *hashTrie[V]) set(shift uint, leaf *hashSlot[V]) *hashTrie[V] {
+	var bitmap uint32
+	var slots []*hashSlot[V]
+	if trie != nil {
+		bitmap, slots = trie.bitmap, trie.slots
+	}
+
+	bit := uint32(1) << (leaf.hash >> shift & hashTrieMask)
+	position := bits.OnesCount32(bitmap & (bit - 1))
+	result := &hashTrie[V]{bitmap: bitmap | bit}
+	if bitmap&bit == 0 {
+		result.slots = make([]*hashSlot[V], 0, len(slots)+1)
+		result.slots = append(result.slots, slots[:position]...)
+		result.slots = append(result.slots, leaf)
+		result.slots = append(result.slots, slots[position:]...)
+		return result
+	}
+
+	result.slots = append([]*hashSlot[V](nil), slots...)
+	switch current := slots[position]; {
+	case current.child != nil:
+		result.slots[position] = &hashSlot[V]{child: current.child.set(shift+hashTrieBits, leaf)}
+	case current.hash == leaf.hash:
+		result.slots[position] = leaf
+	default:
+		// Разные хэши с общим префиксом расходятся на следующих уровнях
+		var child *hashTrie[V]
+		child = child.set(shift+hashTrieBits, current).set(shift+hashTrieBits, leaf)
+		result.slots[position] = &hashSlot[V]{child: child}
+	}
+	return result
+}
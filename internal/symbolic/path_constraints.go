@@ -0,0 +1,232 @@
+package symbolic
+
+// PathConstraints — неизменяемый список булевых конъюнктов условия пути.
+// Каждый узел хранит последний конъюнкт и ссылку на список предыдущих,
+// поэтому состояния, разветвившиеся из общего предка, разделяют общий префикс,
+// а ветвление не копирует условие. Пустой список представлен nil.
+//
+// Вместе с конъюнктом узел хранит неизменяемые индексы всего списка: множество
+// конъюнктов по хэшам и систему непересекающихся множеств групп, связанных
+// общими переменными. Индексы обновляются при добавлении конъюнкта за O(log n)
+// и разделяются с предками так же, как сам список
+type PathConstraints struct {
+	parent     *PathConstraints
+	constraint SymbolicExpression
+	variables  []string // переменные constraint в порядке первого вхождения
+	length     int
+
+	members *hashTrie[SymbolicExpression] // хэш -> конъюнкты списка с этим хэшем
+	owners  *hashTrie[variableOwner]      // хэш имени -> первые конъюнкты переменных
+	roots   *hashTrie[int]                // номер конъюнкта -> родитель в его группе
+}
+
+// variableOwner — номер первого конъюнкта, в который входит переменная name
+type variableOwner struct {
+	name  string
+	index int
+}
+
+// Add возвращает список с добавленным условием. Конъюнкция добавляется по конъюнктам,
+// истина и уже имеющиеся конъюнкты пропускаются. Исходный список не изменяется
+func (pc *PathConstraints) Add(condition SymbolicExpression) *PathConstraints {
+	result := pc
+	for _, constraint := range Conjuncts(condition) {
+		if result.Contains(constraint) {
+			continue
+		}
+		result = result.push(constraint)
+	}
+	return result
+}
+
+// push возвращает список с конъюнктом constraint, обновляя индексы
+func (pc *PathConstraints) push(constraint SymbolicExpression) *PathConstraints {
+	node := &PathConstraints{
+		parent:     pc,
+		constraint: constraint,
+		variables:  Variables(constraint),
+		length:     pc.Len() + 1,
+	}
+	if pc != nil {
+		node.members, node.owners, node.roots = pc.members, pc.owners, pc.roots
+	}
+
+	hash := constraint.Hash()
+	node.members = node.members.with(hash, appendShared(node.members.lookup(hash), constraint))
+
+	// Конъюнкт объединяет группы всех своих переменных. Корнем группы
+	// остаётся её первый конъюнкт, чтобы группы упорядочивались по нему
+	index := node.length - 1
+	groups := []int{index}
+	for _, name := range node.variables {
+		nameHash := HashString(name)
+		owners := node.owners.lookup(nameHash)
+		owner := -1
+		for _, candidate := range owners {
+			if candidate.name == name {
+				owner = candidate.index
+				break
+			}
+		}
+		if owner < 0 {
+			node.owners = node.owners.with(nameHash, appendShared(owners, variableOwner{name, index}))
+			continue
+		}
+		groups = append(groups, node.find(owner))
+	}
+
+	root := index
+	for _, group := range groups {
+		root = min(root, group)
+	}
+	for _, group := range groups {
+		if group != root {
+			node.roots = node.roots.with(uint64(group), []int{root})
+		}
+	}
+	return node
+}
+
+// appendShared добавляет value в копию values, не затрагивая общий массив
+func appendShared[V any](values []V, value V) []V {
+	return append(values[:len(values):len(values)], value)
+}
+
+// find возвращает корень группы конъюнкта с номером index
+func (pc *PathConstraints) find(index int) int {
+	for {
+		parent := pc.roots.lookup(uint64(index))
+		if len(parent) == 0 {
+			return index
+		}
+		index = parent[0]
+	}
+}
+
+// Fork возвращает список для нового состояния. Список неизменяем,
+// поэтому ветви разделяют его, а добавления в одной ветви не видны в другой
+func (pc *PathConstraints) Fork() *PathConstraints {
+	return pc
+}
+
+// Len возвращает число конъюнктов
+func (pc *PathConstraints) Len() int {
+	if pc == nil {
+		return 0
+	}
+	return pc.length
+}
+
+// Parent возвращает список без последнего конъюнкта
+func (pc *PathConstraints) Parent() *PathConstraints {
+	if pc == nil {
+		return nil
+	}
+	return pc.parent
+}
+
+// Last возвращает последний добавленный конъюнкт или nil для пустого списка
+func (pc *PathConstraints) Last() SymbolicExpression {
+	if pc == nil {
+		return nil
+	}
+	return pc.constraint
+}
+
+// Constraints возвращает конъюнкты в порядке добавления
+func (pc *PathConstraints) Constraints() []SymbolicExpression {
+	result := make([]SymbolicExpression, pc.Len())
+	for node := pc; node != nil; node = node.parent {
+		result[node.length-1] = node.constraint
+	}
+	return result
+}
+
+// Contains сообщает, есть ли в списке конъюнкт, структурно равный constraint
+func (pc *PathConstraints) Contains(constraint SymbolicExpression) bool {
+	if pc == nil {
+		return false
+	}
+	for _, member := range pc.members.lookup(constraint.Hash()) {
+		if Equal(member, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mentioning возвращает конъюнкты, в которые входит переменная или символьная ссылка name,
+// в порядке добавления
+func (pc *PathConstraints) Mentioning(name string) []SymbolicExpression {
+	var result []SymbolicExpression
+	for _, node := range pc.nodes() {
+		for _, variable := range node.variables {
+			if variable == name {
+				result = append(result, node.constraint)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Variables возвращает имена переменных и символьных ссылок всех конъюнктов
+func (pc *PathConstraints) Variables() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, node := range pc.nodes() {
+		for _, name := range node.variables {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Partitions разбивает конъюнкты на независимые группы: конъюнкты попадают в одну группу,
+// если связаны цепочкой общих переменных. Группы упорядочены по первому конъюнкту,
+// конъюнкты внутри группы — в порядке добавления. Корни групп поддерживаются
+// при добавлении, поэтому разбиение не требует повторного объединения групп
+func (pc *PathConstraints) Partitions() [][]SymbolicExpression {
+	nodes := pc.nodes()
+	var partitions [][]SymbolicExpression
+	indices := make(map[int]int) // корень группы -> номер группы
+	for i, node := range nodes {
+		root := pc.find(i)
+		index, ok := indices[root]
+		if !ok {
+			index = len(partitions)
+			indices[root] = index
+			partitions = append(partitions, nil)
+		}
+		partitions[index] = append(partitions[index], node.constraint)
+	}
+	return partitions
+}
+
+// Expression возвращает конъюнкцию списка одним выражением для транслятора
+func (pc *PathConstraints) Expression() SymbolicExpression {
+	switch pc.Len() {
+	case 0:
+		return NewBoolConstant(true)
+	case 1:
+		return pc.constraint
+	default:
+		return NewLogicalOperation(pc.Constraints(), AND)
+	}
+}
+
+func (pc *PathConstraints) String() string {
+	return pc.Expression().String()
+}
+
+// nodes возвращает узлы списка в порядке добавления
+func (pc *PathConstraints) nodes() []*PathConstraints {
+	result := make([]*PathConstraints, pc.Len())
+	for node := pc; node != nil; node = node.parent {
+		result[node.length-1] = node
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package symbolic
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestPathConstraints тестирует разделение префикса ветвями и поиск конъюнктов по переменной
+func TestPathConstraints(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	y := NewSymbolicVariable("y", IntType)
+	positive := NewBinaryOperation(x, NewIntConstant(0), GT)
+	ordered := NewBinaryOperation(x, y, LT)
+
+	var empty *PathConstraints
+	if empty.Len() != 0 || empty.String() != "true" || len(empty.Constraints()) != 0 {
+		t.Errorf("Expected empty constraints to be true, got %s", empty)
+	}
+
+	parent := empty.Add(NewLogicalOperation([]SymbolicExpression{positive, NewBoolConstant(true)}, AND))
+	parent = parent.Add(positive)
+	if parent.Len() != 1 || parent.String() != "(x > 0)" {
+		t.Errorf("Expected conjunction to be split and deduplicated, got %s", parent)
+	}
+
+	left := parent.Fork().Add(ordered)
+	right := parent.Fork().Add(NewLogicalOperation([]SymbolicExpression{ordered}, NOT))
+	if left.Parent() != parent || right.Parent() != parent {
+		t.Errorf("Expected branches to share the parent")
+	}
+	if parent.Len() != 1 || left.Len() != 2 || right.String() != "((x > 0) && !(x < y))" {
+		t.Errorf("Unexpected branches %s and %s of %s", left, right, parent)
+	}
+	if left.Last() != ordered {
+		t.Errorf("Expected last constraint %s, got %s", ordered, left.Last())
+	}
+
+	if mentioning := left.Mentioning("y"); len(mentioning) != 1 || mentioning[0] != ordered {
+		t.Errorf("Expected only %s to mention y, got %v", ordered, mentioning)
+	}
+	if mentioning := left.Mentioning("x"); len(mentioning) != 2 {
+		t.Errorf("Expected both constraints to mention x, got %v", mentioning)
+	}
+	if variables := left.Variables(); len(variables) != 2 || variables[0] != "x" || variables[1] != "y" {
+		t.Errorf("Expected variables [x y], got %v", variables)
+	}
+
+	constraints := left.Constraints()
+	if len(constraints) != 2 || constraints[0] != positive || constraints[1] != ordered {
+		t.Errorf("Expected constraints in insertion order, got %v", constraints)
+	}
+}
+
+// TestVariables тестирует сбор переменных и символьных ссылок
+func TestVariables(t *testing.T) {
+	array := NewArrayVariable("a", Int64Type, Int64Type)
+	i := NewSymbolicVariable("i", Int64Type)
+	expr := NewLogicalOperation([]SymbolicExpression{
+		NewBinaryOperation(NewArraySelect(array, i), i, EQ),
+		NewBinaryOperation(NewSymbolicRef(3, RefType), NewRef(1, RefType), NE),
+	}, OR)
+
+	variables := Variables(expr)
+	if len(variables) != 3 || variables[0] != "a" || variables[1] != "i" || variables[2] != "ref_3" {
+		t.Errorf("Expected [a i ref_3], got %v", variables)
+	}
+}
+
+// TestPartitions тестирует разбиение конъюнктов на группы, связанные общими переменными
+func TestPartitions(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	y := NewSymbolicVariable("y", IntType)
+	z := NewSymbolicVariable("z", IntType)
+	w := NewSymbolicVariable("w", IntType)
+
+	var pc *PathConstraints
+	for _, constraint := range []SymbolicExpression{
+		NewBinaryOperation(x, NewIntConstant(0), GT),
+		NewBinaryOperation(y, NewIntConstant(0), GT),
+		NewBinaryOperation(z, w, LT),
+		NewBinaryOperation(x, z, EQ),
+		NewBinaryOperation(y, NewIntConstant(5), LT),
+	} {
+		pc = pc.Add(constraint)
+	}
+
+	partitions := pc.Partitions()
+	if len(partitions) != 2 {
+		t.Fatalf("Expected 2 partitions, got %v", partitions)
+	}
+	expected := []string{"[(x > 0) (z < w) (x == z)]", "[(y > 0) (y < 5)]"}
+	for i, partition := range partitions {
+		if actual := fmt.Sprint(partition); actual != expected[i] {
+			t.Errorf("Expected partition %s, got %s", expected[i], actual)
+		}
+	}
+
+	if prefix := pc.Parent().Parent().Partitions(); len(prefix) != 3 {
+		t.Errorf("Expected later merge not to affect the prefix, got %v", prefix)
+	}
+
+	var empty *PathConstraints
+	if partitions := empty.Partitions(); len(partitions) != 0 {
+		t.Errorf("Expected no partitions for empty constraints, got %v", partitions)
+	}
+}
+
+// TestHashTrie тестирует неизменяемость версий и хэши с общим префиксом
+func TestHashTrie(t *testing.T) {
+	var trie *hashTrie[string]
+	hashes := []uint64{0, 1, 1 << 5, 1 << 60, 1<<60 | 1, 33}
+
+	versions := make([]*hashTrie[string], len(hashes))
+	for i, hash := range hashes {
+		trie = trie.with(hash, []string{fmt.Sprint(i)})
+		versions[i] = trie
+	}
+
+	for i, version := range versions {
+		for j, hash := range hashes {
+			values := version.lookup(hash)
+			if j <= i && (len(values) != 1 || values[0] != fmt.Sprint(j)) {
+				t.Errorf("Version %d: expected %d for hash %d, got %v", i, j, hash, values)
+			}
+			if j > i && values != nil {
+				t.Errorf("Version %d: unexpected %v for hash %d", i, values, hash)
+			}
+		}
+	}
+
+	replaced := trie.with(1<<60, []string{"new"})
+	if values := replaced.lookup(1 << 60); len(values) != 1 || values[0] != "new" {
+		t.Errorf("Expected replaced value, got %v", values)
+	}
+	if values := trie.lookup(1 << 60); values[0] != "3" {
+		t.Errorf("Replacement changed previous version: %v", values)
+	}
+}
@@ -0,0 +1,582 @@
+package symbolic
+
+import (
+	"math"
+	"math/big"
+)
+
+// Simplify возвращает упрощённое выражение, эквивалентное expr
+func Simplify(expr SymbolicExpression) SymbolicExpression {
+	return expr.Accept(&Simplifier{}).(SymbolicExpression)
+}
+
+// Simplifier упрощает выражение снизу вверх: сворачивает константы, применяет
+// алгебраические тождества и законы поглощения, выпрямляет вложенные AND/OR
+// и приводит сравнения к виду, в котором константа стоит справа.
+// Целые типы неограниченной ширины не сворачиваются при переполнении int64,
+// а их деление не сворачивается, так как в Z3 оно евклидово
+type Simplifier struct{}
+
+func (s *Simplifier) simplify(expr SymbolicExpression) SymbolicExpression {
+	return expr.Accept(s).(SymbolicExpression)
+}
+
+func (s *Simplifier) VisitVariable(expr *SymbolicVariable) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitBitVecConstant(expr *BitVecConstant) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitFloatConstant(expr *FloatConstant) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitRef(expr *Ref) interface{} {
+	return expr
+}
+
+func (s *Simplifier) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	left := s.simplify(expr.Left)
+	right := s.simplify(expr.Right)
+	op := expr.Operator
+
+	if folded := foldBinary(left, right, op); folded != nil {
+		return folded
+	}
+
+	// Константа переносится вправо: в сравнениях с зеркальным оператором,
+	// в коммутативных целочисленных операциях — без изменения оператора
+	if isConstant(left) && !isConstant(right) {
+		if mirrored, ok := mirroredComparisons[op]; ok {
+			left, right, op = right, left, mirrored
+		} else if commutative[op] && left.Type().IsInteger() {
+			left, right = right, left
+		}
+	}
+
+	if identity := binaryIdentity(left, right, op); identity != nil {
+		return identity
+	}
+	return NewBinaryOperation(left, right, op)
+}
+
+func (s *Simplifier) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	switch expr.Operator {
+	case NOT:
+		return negate(s.simplify(expr.Operands[0]))
+	case AND, OR:
+		operands := make([]SymbolicExpression, len(expr.Operands))
+		for i, operand := range expr.Operands {
+			operands[i] = s.simplify(operand)
+		}
+		return junction(operands, expr.Operator)
+	case IMPLIES:
+		premise := s.simplify(expr.Operands[0])
+		conclusion := s.simplify(expr.Operands[1])
+		return junction([]SymbolicExpression{negate(premise), conclusion}, OR)
+	default:
+		return expr
+	}
+}
+
+func (s *Simplifier) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	operand := s.simplify(expr.Operand)
+
+	switch expr.Operator {
+	case UNARY_NOT:
+		return negate(operand)
+	case UNARY_MINUS:
+		switch c := operand.(type) {
+		case *IntConstant:
+			if c.Value != math.MinInt64 {
+				return NewIntConstant(-c.Value)
+			}
+		case *BitVecConstant:
+			return NewBitVecConstant(-c.Value, c.ExprType)
+		case *FloatConstant:
+			return NewFloatConstant(-c.Value, c.ExprType)
+		}
+	case UNARY_COMPLEMENT:
+		if c, ok := operand.(*BitVecConstant); ok {
+			return NewBitVecConstant(^c.Value, c.ExprType)
+		}
+	}
+
+	// Двойное применение минуса или дополнения сокращается
+	if inner, ok := operand.(*UnaryOperation); ok && inner.Operator == expr.Operator {
+		return inner.Operand
+	}
+	return NewUnaryOperation(operand, expr.Operator)
+}
+
+func (s *Simplifier) VisitConversion(expr *Conversion) interface{} {
+	operand := s.simplify(expr.Operand)
+	if operand.Type() == expr.ExprType {
+		return operand
+	}
+
+	switch c := operand.(type) {
+	case *BitVecConstant:
+		if expr.ExprType.IsBitVector() {
+			return NewBitVecConstant(c.Value, expr.ExprType)
+		}
+		if expr.ExprType == IntType && (c.ExprType.IsSigned() || c.Value >= 0) {
+			return NewIntConstant(c.Value)
+		}
+	case *IntConstant:
+		if expr.ExprType.IsBitVector() {
+			return NewBitVecConstant(c.Value, expr.ExprType)
+		}
+	}
+	return NewConversion(operand, expr.ExprType)
+}
+
+func (s *Simplifier) VisitIte(expr *IteExpression) interface{} {
+	cond := s.simplify(expr.Cond)
+	then := s.simplify(expr.Then)
+	els := s.simplify(expr.Else)
+
+	if c, ok := cond.(*BoolConstant); ok {
+		if c.Value {
+			return then
+		}
+		return els
+	}
+	if Equal(then, els) {
+		return then
+	}
+	if thenConstant, ok := then.(*BoolConstant); ok {
+		if elseConstant, ok := els.(*BoolConstant); ok {
+			// Ветви различны, иначе сработало бы правило выше
+			if thenConstant.Value && !elseConstant.Value {
+				return cond
+			}
+			return negate(cond)
+		}
+	}
+	return NewIteExpression(cond, then, els)
+}
+
+func (s *Simplifier) VisitArrayConstant(expr *ArrayConstant) interface{} {
+	return &ArrayConstant{IndexType: expr.IndexType, ElemType: expr.ElemType, Default: s.simplify(expr.Default)}
+}
+
+// VisitArraySelect читает значение из цепочки записей, если индексы константные
+func (s *Simplifier) VisitArraySelect(expr *ArraySelect) interface{} {
+	array := s.simplify(expr.Array)
+	index := s.simplify(expr.Index)
+
+	for {
+		switch a := array.(type) {
+		case *ArrayConstant:
+			return a.Default
+		case *ArrayStore:
+			equal, known := constantEquality(a.Index, index)
+			if !known {
+				return NewArraySelect(array, index)
+			}
+			if equal {
+				return a.Value
+			}
+			array = a.Array
+			continue
+		}
+		return NewArraySelect(array, index)
+	}
+}
+
+func (s *Simplifier) VisitArrayStore(expr *ArrayStore) interface{} {
+	return NewArrayStore(s.simplify(expr.Array), s.simplify(expr.Index), s.simplify(expr.Value))
+}
+
+// Conjoin строит упрощённую конъюнкцию уже упрощённых выражений, не обходя их повторно
+func Conjoin(operands ...SymbolicExpression) SymbolicExpression {
+	return junction(operands, AND)
+}
+
+// Conjuncts возвращает конъюнкты условия в порядке их добавления через Conjoin.
+// Вложенные конъюнкции раскрываются, истина пропускается
+func Conjuncts(condition SymbolicExpression) []SymbolicExpression {
+	switch c := condition.(type) {
+	case *LogicalOperation:
+		if c.Operator == AND {
+			var result []SymbolicExpression
+			for _, operand := range c.Operands {
+				result = append(result, Conjuncts(operand)...)
+			}
+			return result
+		}
+	case *BoolConstant:
+		if c.Value {
+			return nil
+		}
+	}
+	return []SymbolicExpression{condition}
+}
+
+// mirroredComparisons сопоставляет сравнению оператор для переставленных операндов
+var mirroredComparisons = map[BinaryOperator]BinaryOperator{
+	EQ: EQ,
+	NE: NE,
+	LT: GT,
+	LE: GE,
+	GT: LT,
+	GE: LE,
+}
+
+// negatedComparisons сопоставляет сравнению его отрицание.
+// Для чисел с плавающей точкой верно только для EQ и NE из-за NaN
+var negatedComparisons = map[BinaryOperator]BinaryOperator{
+	EQ: NE,
+	NE: EQ,
+	LT: GE,
+	LE: GT,
+	GT: LE,
+	GE: LT,
+}
+
+var commutative = map[BinaryOperator]bool{
+	ADD:     true,
+	MUL:     true,
+	BIT_AND: true,
+	BIT_OR:  true,
+	BIT_XOR: true,
+}
+
+func isConstant(expr SymbolicExpression) bool {
+	switch expr.(type) {
+	case *IntConstant, *BoolConstant, *BitVecConstant, *FloatConstant:
+		return true
+	default:
+		return false
+	}
+}
+
+// integerValue возвращает значение целочисленной константы
+func integerValue(expr SymbolicExpression) (int64, bool) {
+	switch c := expr.(type) {
+	case *IntConstant:
+		return c.Value, true
+	case *BitVecConstant:
+		return c.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// binaryIdentity применяет тождества с нейтральными и поглощающими элементами.
+// Константа к этому моменту стоит справа
+func binaryIdentity(left, right SymbolicExpression, op BinaryOperator) SymbolicExpression {
+	if left.Type().IsInteger() {
+		if value, ok := integerValue(right); ok {
+			switch {
+			case value == 0 && (op == ADD || op == SUB || op == BIT_OR || op == BIT_XOR || op == BIT_AND_NOT || op == SHL || op == SHR):
+				return left
+			case value == 0 && (op == MUL || op == BIT_AND):
+				return right
+			case value == 1 && (op == MUL || op == DIV):
+				return left
+			}
+		}
+		if Equal(left, right) {
+			switch op {
+			case EQ, LE, GE:
+				return NewBoolConstant(true)
+			case NE, LT, GT:
+				return NewBoolConstant(false)
+			case SUB, BIT_XOR:
+				return ZeroValue(left.Type())
+			case BIT_AND, BIT_OR:
+				return left
+			}
+		}
+	}
+
+	if c, ok := right.(*BoolConstant); ok && left.Type() == BoolType {
+		switch op {
+		case EQ:
+			if c.Value {
+				return left
+			}
+			return negate(left)
+		case NE:
+			if c.Value {
+				return negate(left)
+			}
+			return left
+		}
+	}
+	return nil
+}
+
+// negate строит отрицание упрощённого выражения
+func negate(expr SymbolicExpression) SymbolicExpression {
+	switch e := expr.(type) {
+	case *BoolConstant:
+		return NewBoolConstant(!e.Value)
+	case *UnaryOperation:
+		if e.Operator == UNARY_NOT {
+			return e.Operand
+		}
+	case *LogicalOperation:
+		if e.Operator == NOT {
+			return e.Operands[0]
+		}
+	case *BinaryOperation:
+		negated, ok := negatedComparisons[e.Operator]
+		if ok && (!e.Left.Type().IsFloat() || e.Operator == EQ || e.Operator == NE) {
+			return NewBinaryOperation(e.Left, e.Right, negated)
+		}
+	}
+	return NewLogicalOperation([]SymbolicExpression{expr}, NOT)
+}
+
+// junction строит упрощённую конъюнкцию или дизъюнкцию упрощённых операндов:
+// выпрямляет вложенные операции того же вида, убирает нейтральные константы и повторы,
+// применяет законы поглощения и находит пары противоположных операндов
+func junction(operands []SymbolicExpression, op LogicalOperator) SymbolicExpression {
+	identity := op == AND
+
+	var flat []SymbolicExpression
+	for _, operand := range operands {
+		if nested, ok := operand.(*LogicalOperation); ok && nested.Operator == op {
+			flat = append(flat, nested.Operands...)
+		} else {
+			flat = append(flat, operand)
+		}
+	}
+
+	var result []SymbolicExpression
+	seen := make(ExpressionSet)
+	for _, operand := range flat {
+		if c, ok := operand.(*BoolConstant); ok {
+			if c.Value != identity {
+				return NewBoolConstant(!identity)
+			}
+			continue
+		}
+		if seen.Contains(operand) {
+			continue
+		}
+		if seen.Contains(negate(operand)) {
+			return NewBoolConstant(!identity)
+		}
+		seen.Add(operand)
+		result = append(result, operand)
+	}
+
+	// Поглощение: a && (a || b) = a, a || (a && b) = a
+	dual := OR
+	if op == OR {
+		dual = AND
+	}
+	var absorbed []SymbolicExpression
+	for _, operand := range result {
+		if inner, ok := operand.(*LogicalOperation); ok && inner.Operator == dual && containsAny(inner.Operands, seen) {
+			continue
+		}
+		absorbed = append(absorbed, operand)
+	}
+
+	switch len(absorbed) {
+	case 0:
+		return NewBoolConstant(identity)
+	case 1:
+		return absorbed[0]
+	default:
+		return NewLogicalOperation(absorbed, op)
+	}
+}
+
+func containsAny(operands []SymbolicExpression, set ExpressionSet) bool {
+	for _, operand := range operands {
+		if set.Contains(operand) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpressionSet — множество выражений с точностью до структурного равенства
+type ExpressionSet map[uint64][]SymbolicExpression
+
+func (set ExpressionSet) Contains(expr SymbolicExpression) bool {
+	for _, candidate := range set[expr.Hash()] {
+		if Equal(candidate, expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set ExpressionSet) Add(expr SymbolicExpression) {
+	hash := expr.Hash()
+	set[hash] = append(set[hash], expr)
+}
+
+// constantEquality сравнивает два индекса, если оба константные
+func constantEquality(left, right SymbolicExpression) (equal bool, known bool) {
+	leftValue, leftOk := integerValue(left)
+	rightValue, rightOk := integerValue(right)
+	if leftOk && rightOk {
+		return leftValue == rightValue, true
+	}
+	if Equal(left, right) {
+		return true, true
+	}
+	return false, false
+}
+
+// foldBinary вычисляет операцию над двумя константами или возвращает nil
+func foldBinary(left, right SymbolicExpression, op BinaryOperator) SymbolicExpression {
+	switch l := left.(type) {
+	case *IntConstant:
+		if r, ok := right.(*IntConstant); ok {
+			return foldInt(l.Value, r.Value, op)
+		}
+	case *BitVecConstant:
+		if r, ok := right.(*BitVecConstant); ok {
+			return foldBitVec(l, r, op)
+		}
+	case *FloatConstant:
+		if r, ok := right.(*FloatConstant); ok {
+			return foldFloat(l.Value, r.Value, l.ExprType, op)
+		}
+	case *BoolConstant:
+		if r, ok := right.(*BoolConstant); ok {
+			switch op {
+			case EQ:
+				return NewBoolConstant(l.Value == r.Value)
+			case NE:
+				return NewBoolConstant(l.Value != r.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func foldInt(left, right int64, op BinaryOperator) SymbolicExpression {
+	if result := compare(left, right, op); result != nil {
+		return result
+	}
+
+	l, r := big.NewInt(left), big.NewInt(right)
+	var result *big.Int
+	switch op {
+	case ADD:
+		result = new(big.Int).Add(l, r)
+	case SUB:
+		result = new(big.Int).Sub(l, r)
+	case MUL:
+		result = new(big.Int).Mul(l, r)
+	default:
+		return nil
+	}
+	if !result.IsInt64() {
+		return nil
+	}
+	return NewIntConstant(result.Int64())
+}
+
+func foldBitVec(left, right *BitVecConstant, op BinaryOperator) SymbolicExpression {
+	exprType := left.ExprType
+	if op == SHL || op == SHR {
+		return nil
+	}
+	if exprType != right.ExprType {
+		return nil
+	}
+
+	if exprType.IsSigned() {
+		if result := compare(left.Value, right.Value, op); result != nil {
+			return result
+		}
+	} else if result := compare(uint64(left.Value), uint64(right.Value), op); result != nil {
+		return result
+	}
+
+	l, r := left.Value, right.Value
+	var result int64
+	switch op {
+	case ADD:
+		result = l + r
+	case SUB:
+		result = l - r
+	case MUL:
+		result = l * r
+	case DIV, MOD:
+		if r == 0 {
+			return nil
+		}
+		switch {
+		case exprType.IsSigned() && op == DIV:
+			result = l / r
+		case exprType.IsSigned():
+			result = l % r
+		case op == DIV:
+			result = int64(uint64(l) / uint64(r))
+		default:
+			result = int64(uint64(l) % uint64(r))
+		}
+	case BIT_AND:
+		result = l & r
+	case BIT_OR:
+		result = l | r
+	case BIT_XOR:
+		result = l ^ r
+	case BIT_AND_NOT:
+		result = l &^ r
+	default:
+		return nil
+	}
+	return NewBitVecConstant(result, exprType)
+}
+
+func foldFloat(left, right float64, exprType ExpressionType, op BinaryOperator) SymbolicExpression {
+	if result := compare(left, right, op); result != nil {
+		return result
+	}
+
+	switch op {
+	case ADD:
+		return NewFloatConstant(left+right, exprType)
+	case SUB:
+		return NewFloatConstant(left-right, exprType)
+	case MUL:
+		return NewFloatConstant(left*right, exprType)
+	case DIV:
+		return NewFloatConstant(left/right, exprType)
+	default:
+		return nil
+	}
+}
+
+// compare вычисляет сравнение или возвращает nil для других операторов
+func compare[T int64 | uint64 | float64](left, right T, op BinaryOperator) SymbolicExpression {
+	switch op {
+	case EQ:
+		return NewBoolConstant(left == right)
+	case NE:
+		return NewBoolConstant(left != right)
+	case LT:
+		return NewBoolConstant(left < right)
+	case LE:
+		return NewBoolConstant(left <= right)
+	case GT:
+		return NewBoolConstant(left > right)
+	case GE:
+		return NewBoolConstant(left >= right)
+	default:
+		return nil
+	}
+}
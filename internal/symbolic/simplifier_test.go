@@ -0,0 +1,96 @@
+package symbolic
+
+import "testing"
+
+// TestSimplify тестирует свёртку констант, тождества и нормализацию выражений
+func TestSimplify(t *testing.T) {
+	x := NewSymbolicVariable("x", Int64Type)
+	f := NewSymbolicVariable("f", Float64Type)
+	a := NewSymbolicVariable("a", BoolType)
+	b := NewSymbolicVariable("b", BoolType)
+	c := NewSymbolicVariable("c", BoolType)
+	five := NewBitVecConstant(5, Int64Type)
+
+	not := func(operand SymbolicExpression) SymbolicExpression {
+		return NewLogicalOperation([]SymbolicExpression{operand}, NOT)
+	}
+	and := func(operands ...SymbolicExpression) SymbolicExpression {
+		return NewLogicalOperation(operands, AND)
+	}
+	or := func(operands ...SymbolicExpression) SymbolicExpression {
+		return NewLogicalOperation(operands, OR)
+	}
+	array := NewArrayStore(NewArrayConstant(Int64Type, NewBitVecConstant(0, Int64Type)), NewBitVecConstant(1, Int64Type), five)
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		expected string
+	}{
+		{"add zero", NewBinaryOperation(x, NewBitVecConstant(0, Int64Type), ADD), "x"},
+		{"zero add", NewBinaryOperation(NewBitVecConstant(0, Int64Type), x, ADD), "x"},
+		{"multiply by zero", NewBinaryOperation(x, NewBitVecConstant(0, Int64Type), MUL), "0"},
+		{"subtract self", NewBinaryOperation(x, x, SUB), "0"},
+		{"nested constants", NewBinaryOperation(NewBinaryOperation(NewBitVecConstant(0, Int64Type), NewBitVecConstant(1, Int64Type), ADD), x, LT), "(x > 1)"},
+		{"int8 overflow", NewBinaryOperation(NewBitVecConstant(127, Int8Type), NewBitVecConstant(1, Int8Type), ADD), "-128"},
+		{"uint8 wraparound", NewBinaryOperation(NewBitVecConstant(200, Uint8Type), NewBitVecConstant(100, Uint8Type), ADD), "44"},
+		{"unsigned comparison", NewBinaryOperation(NewBitVecConstant(-1, Uint64Type), NewBitVecConstant(1, Uint64Type), GT), "true"},
+		{"signed division", NewBinaryOperation(NewBitVecConstant(-7, Int32Type), NewBitVecConstant(2, Int32Type), DIV), "-3"},
+		{"division by zero", NewBinaryOperation(five, NewBitVecConstant(0, Int64Type), DIV), "(5 / 0)"},
+		{"unbounded overflow", NewBinaryOperation(NewIntConstant(1<<62), NewIntConstant(1<<62), ADD), "(4611686018427387904 + 4611686018427387904)"},
+		{"float folding", NewBinaryOperation(NewFloatConstant(0.5, Float64Type), NewFloatConstant(0.25, Float64Type), ADD), "0.75"},
+		{"float identity kept", NewBinaryOperation(f, NewFloatConstant(0, Float64Type), ADD), "(f + 0)"},
+		{"constant comparison", NewBinaryOperation(five, NewBitVecConstant(3, Int64Type), GT), "true"},
+		{"constant moved right", NewBinaryOperation(five, x, GT), "(x < 5)"},
+		{"double negation", not(not(a)), "a"},
+		{"unary double negation", NewUnaryOperation(NewUnaryOperation(a, UNARY_NOT), UNARY_NOT), "a"},
+		{"negated comparison", not(NewBinaryOperation(x, five, LT)), "(x >= 5)"},
+		{"negated float comparison", not(NewBinaryOperation(f, NewFloatConstant(1, Float64Type), LT)), "!(f < 1)"},
+		{"bool equality", NewBinaryOperation(a, NewBoolConstant(false), EQ), "!a"},
+		{"flatten", and(a, and(b, c)), "(a && b && c)"},
+		{"neutral constants", and(a, NewBoolConstant(true), b), "(a && b)"},
+		{"annihilator", or(a, NewBoolConstant(true)), "true"},
+		{"duplicates", or(a, b, a), "(a || b)"},
+		{"absorption", and(a, or(a, b)), "a"},
+		{"dual absorption", or(a, and(b, a)), "a"},
+		{"contradiction", and(a, b, not(a)), "false"},
+		{"excluded middle", or(NewBinaryOperation(x, five, LT), NewBinaryOperation(x, five, GE)), "true"},
+		{"implication", NewLogicalOperation([]SymbolicExpression{NewBoolConstant(true), a}, IMPLIES), "a"},
+		{"constant ite", NewIteExpression(NewBoolConstant(true), x, five), "x"},
+		{"boolean ite", NewIteExpression(a, NewBoolConstant(false), NewBoolConstant(true)), "!a"},
+		{"select stored", NewArraySelect(array, NewBitVecConstant(1, Int64Type)), "5"},
+		{"select default", NewArraySelect(array, NewBitVecConstant(2, Int64Type)), "0"},
+		{"select symbolic", NewArraySelect(array, x), "const(0){1 := 5}[x]"},
+		{"constant conversion", NewConversion(NewBitVecConstant(300, Int64Type), Uint8Type), "44"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simplified := Simplify(tt.expr)
+			if simplified.String() != tt.expected {
+				t.Errorf("Simplify(%s) = %s, want %s", tt.expr, simplified, tt.expected)
+			}
+			if simplified.Type() != tt.expr.Type() {
+				t.Errorf("Simplify changed type from %s to %s", tt.expr.Type(), simplified.Type())
+			}
+		})
+	}
+}
+
+// TestConjoin тестирует добавление условий к упрощённому условию пути
+func TestConjoin(t *testing.T) {
+	a := NewSymbolicVariable("a", BoolType)
+	b := NewSymbolicVariable("b", BoolType)
+
+	pathCondition := Conjoin(NewBoolConstant(true), a)
+	pathCondition = Conjoin(pathCondition, b)
+	pathCondition = Conjoin(pathCondition, a)
+	if pathCondition.String() != "(a && b)" {
+		t.Errorf("Expected flat path condition, got %s", pathCondition)
+	}
+
+	negation := NewLogicalOperation([]SymbolicExpression{b}, NOT)
+	if result := Conjoin(pathCondition, Simplify(negation)); result.String() != "false" {
+		t.Errorf("Expected contradiction, got %s", result)
+	}
+}
@@ -0,0 +1,87 @@
+package symbolic
+
+// Substitute возвращает копию выражения, в которой подвыражения заменены результатом replace.
+// replace вызывается для каждого узла сверху вниз: если он возвращает nil, обходятся
+// операнды узла, иначе узел заменяется целиком.
+// Замена одновременная: подставленные выражения повторно не обходятся
+func Substitute(expr SymbolicExpression, replace func(SymbolicExpression) SymbolicExpression) SymbolicExpression {
+	return (&substitutor{replace: replace}).visit(expr)
+}
+
+// substitutor перестраивает дерево выражения снизу вверх
+type substitutor struct {
+	replace func(SymbolicExpression) SymbolicExpression
+}
+
+func (s *substitutor) visit(expr SymbolicExpression) SymbolicExpression {
+	if replaced := s.replace(expr); replaced != nil {
+		return replaced
+	}
+	return expr.Accept(s).(SymbolicExpression)
+}
+
+func (s *substitutor) VisitVariable(expr *SymbolicVariable) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitBitVecConstant(expr *BitVecConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitFloatConstant(expr *FloatConstant) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitRef(expr *Ref) interface{} {
+	return expr
+}
+
+func (s *substitutor) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	return &BinaryOperation{Left: s.visit(expr.Left), Right: s.visit(expr.Right), Operator: expr.Operator}
+}
+
+func (s *substitutor) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	operands := make([]SymbolicExpression, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = s.visit(operand)
+	}
+	return &LogicalOperation{Operands: operands, Operator: expr.Operator}
+}
+
+func (s *substitutor) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	return &UnaryOperation{Operand: s.visit(expr.Operand), Operator: expr.Operator}
+}
+
+func (s *substitutor) VisitConversion(expr *Conversion) interface{} {
+	return &Conversion{Operand: s.visit(expr.Operand), ExprType: expr.ExprType}
+}
+
+func (s *substitutor) VisitIte(expr *IteExpression) interface{} {
+	return &IteExpression{Cond: s.visit(expr.Cond), Then: s.visit(expr.Then), Else: s.visit(expr.Else)}
+}
+
+func (s *substitutor) VisitArrayConstant(expr *ArrayConstant) interface{} {
+	return &ArrayConstant{IndexType: expr.IndexType, ElemType: expr.ElemType, Default: s.visit(expr.Default)}
+}
+
+func (s *substitutor) VisitArraySelect(expr *ArraySelect) interface{} {
+	return &ArraySelect{Array: s.visit(expr.Array), Index: s.visit(expr.Index)}
+}
+
+func (s *substitutor) VisitArrayStore(expr *ArrayStore) interface{} {
+	return &ArrayStore{
+		Array:     s.visit(expr.Array),
+		Index:     s.visit(expr.Index),
+		Value:     s.visit(expr.Value),
+		IndexType: expr.IndexType,
+		ElemType:  expr.ElemType,
+	}
+}
@@ -10,6 +10,20 @@ const (
 	ArrayType
 	RefType
 	StructType
+
+	// Целые типы фиксированной ширины с семантикой битовых векторов Go
+	Int8Type
+	Int16Type
+	Int32Type
+	Int64Type
+	Uint8Type
+	Uint16Type
+	Uint32Type
+	Uint64Type
+
+	// Числа с плавающей точкой IEEE-754
+	Float32Type
+	Float64Type
 )
 
 // String возвращает строковое представление типа
@@ -25,7 +39,68 @@ func (et ExpressionType) String() string {
 		return "ref"
 	case StructType:
 		return "struct"
+	case Int8Type:
+		return "int8"
+	case Int16Type:
+		return "int16"
+	case Int32Type:
+		return "int32"
+	case Int64Type:
+		return "int64"
+	case Uint8Type:
+		return "uint8"
+	case Uint16Type:
+		return "uint16"
+	case Uint32Type:
+		return "uint32"
+	case Uint64Type:
+		return "uint64"
+	case Float32Type:
+		return "float32"
+	case Float64Type:
+		return "float64"
 	default:
 		return "unknown"
 	}
 }
+
+// IsBitVector сообщает, является ли тип целым типом фиксированной ширины
+func (et ExpressionType) IsBitVector() bool {
+	return et >= Int8Type && et <= Uint64Type
+}
+
+// IsInteger сообщает, является ли тип целочисленным (неограниченным или фиксированной ширины)
+func (et ExpressionType) IsInteger() bool {
+	return et == IntType || et.IsBitVector()
+}
+
+// IsSigned сообщает, является ли целый тип знаковым
+func (et ExpressionType) IsSigned() bool {
+	return et == IntType || (et >= Int8Type && et <= Int64Type)
+}
+
+// BitWidth возвращает ширину битового вектора в битах или 0 для остальных типов
+func (et ExpressionType) BitWidth() int {
+	switch et {
+	case Int8Type, Uint8Type:
+		return 8
+	case Int16Type, Uint16Type:
+		return 16
+	case Int32Type, Uint32Type:
+		return 32
+	case Int64Type, Uint64Type:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// IsFloat сообщает, является ли тип типом с плавающей точкой
+func (et ExpressionType) IsFloat() bool {
+	return et == Float32Type || et == Float64Type
+}
+
+// IsNumeric сообщает, является ли тип числовым (целым или с плавающей точкой)
+func (et ExpressionType) IsNumeric() bool {
+	return et.IsInteger() || et.IsFloat()
+}
@@ -0,0 +1,89 @@
+package symbolic
+
+// Variables возвращает имена переменных и символьных ссылок выражения в порядке первого вхождения.
+// Символьные ссылки решатель выбирает так же, как значения переменных
+func Variables(expr SymbolicExpression) []string {
+	collector := &variableCollector{seen: make(map[string]bool)}
+	expr.Accept(collector)
+	return collector.names
+}
+
+// variableCollector собирает имена переменных при обходе выражения
+type variableCollector struct {
+	seen  map[string]bool
+	names []string
+}
+
+func (c *variableCollector) add(name string) interface{} {
+	if !c.seen[name] {
+		c.seen[name] = true
+		c.names = append(c.names, name)
+	}
+	return nil
+}
+
+func (c *variableCollector) visit(exprs ...SymbolicExpression) interface{} {
+	for _, expr := range exprs {
+		expr.Accept(c)
+	}
+	return nil
+}
+
+func (c *variableCollector) VisitVariable(expr *SymbolicVariable) interface{} {
+	return c.add(expr.Name)
+}
+
+func (c *variableCollector) VisitIntConstant(expr *IntConstant) interface{} {
+	return nil
+}
+
+func (c *variableCollector) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return nil
+}
+
+func (c *variableCollector) VisitBitVecConstant(expr *BitVecConstant) interface{} {
+	return nil
+}
+
+func (c *variableCollector) VisitFloatConstant(expr *FloatConstant) interface{} {
+	return nil
+}
+
+func (c *variableCollector) VisitRef(expr *Ref) interface{} {
+	if expr.Symbolic {
+		return c.add(expr.String())
+	}
+	return nil
+}
+
+func (c *variableCollector) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	return c.visit(expr.Left, expr.Right)
+}
+
+func (c *variableCollector) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	return c.visit(expr.Operands...)
+}
+
+func (c *variableCollector) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	return c.visit(expr.Operand)
+}
+
+func (c *variableCollector) VisitConversion(expr *Conversion) interface{} {
+	return c.visit(expr.Operand)
+}
+
+func (c *variableCollector) VisitIte(expr *IteExpression) interface{} {
+	return c.visit(expr.Cond, expr.Then, expr.Else)
+}
+
+func (c *variableCollector) VisitArrayConstant(expr *ArrayConstant) interface{} {
+	return c.visit(expr.Default)
+}
+
+func (c *variableCollector) VisitArraySelect(expr *ArraySelect) interface{} {
+	return c.visit(expr.Array, expr.Index)
+}
+
+func (c *variableCollector) VisitArrayStore(expr *ArrayStore) interface{} {
+	return c.visit(expr.Array, expr.Index, expr.Value)
+}
@@ -5,8 +5,15 @@ type Visitor interface {
 	VisitVariable(expr *SymbolicVariable) interface{}
 	VisitIntConstant(expr *IntConstant) interface{}
 	VisitBoolConstant(expr *BoolConstant) interface{}
+	VisitBitVecConstant(expr *BitVecConstant) interface{}
+	VisitFloatConstant(expr *FloatConstant) interface{}
 	VisitBinaryOperation(expr *BinaryOperation) interface{}
 	VisitLogicalOperation(expr *LogicalOperation) interface{}
 	VisitUnaryOperation(expr *UnaryOperation) interface{}
 	VisitRef(expr *Ref) interface{}
+	VisitConversion(expr *Conversion) interface{}
+	VisitIte(expr *IteExpression) interface{}
+	VisitArrayConstant(expr *ArrayConstant) interface{}
+	VisitArraySelect(expr *ArraySelect) interface{}
+	VisitArrayStore(expr *ArrayStore) interface{}
 }
@@ -0,0 +1,449 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// TestCase описывает конкретные входные данные и ожидаемый результат одного пути
+type TestCase struct {
+	Name      string
+	Setup     []string // объявления объектов, на которые указывают аргументы
+	Arguments []string // Go-выражения аргументов
+	Expected  string   // Go-выражение результата; пусто, если результат не проверяется
+	UsesMath  bool
+}
+
+// GenerateTestCases решает условия завершённых путей и строит по моделям тестовые случаи.
+// Пути, для которых решатель не нашёл модель, пропускаются
+func (analyser *Analyser) GenerateTestCases() []TestCase {
+	var cases []TestCase
+	for _, result := range analyser.Results {
+		if testCase, ok := analyser.GenerateTestCase(result, fmt.Sprintf("path_%d", len(cases)+1)); ok {
+			cases = append(cases, testCase)
+		}
+	}
+	return cases
+}
+
+// GenerateTestCase строит тестовый случай с именем name по модели условия пути state
+func (analyser *Analyser) GenerateTestCase(state Interpreter, name string) (TestCase, bool) {
+	model := analyser.solve(state)
+	if model == nil {
+		return TestCase{}, false
+	}
+
+	generator := &caseGenerator{
+		analyser: analyser,
+		state:    state,
+		model:    model,
+		objects:  make(map[string]string),
+		testCase: TestCase{Name: name},
+	}
+	return generator.generate(), true
+}
+
+// WriteTestFile генерирует табличный тест для исследованной функции и записывает его в path
+func (analyser *Analyser) WriteTestFile(path string) error {
+	source, err := GenerateTestFile(analyser.Function, analyser.GenerateTestCases())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, source, 0644)
+}
+
+// GenerateTestFile строит исходный код табличного теста функции по тестовым случаям
+func GenerateTestFile(function *ssa.Function, cases []TestCase) ([]byte, error) {
+	signature := function.Signature
+	qualifier := types.RelativeTo(function.Pkg.Pkg)
+	fields := parameterFields(signature)
+	checkResult := hasCheckableResult(signature)
+
+	usesMath := false
+	for _, testCase := range cases {
+		usesMath = usesMath || testCase.UsesMath
+	}
+	if checkResult && isFloat(signature.Results().At(0).Type()) {
+		usesMath = true
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("// Code generated by symbolic execution. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buffer, "package %s\n\n", function.Pkg.Pkg.Name())
+	if usesMath {
+		buffer.WriteString("import (\n\"math\"\n\"testing\"\n)\n\n")
+	} else {
+		buffer.WriteString("import \"testing\"\n\n")
+	}
+
+	fmt.Fprintf(&buffer, "func Test%s(t *testing.T) {\n", exportedName(function.Name()))
+	for _, testCase := range cases {
+		for _, line := range testCase.Setup {
+			buffer.WriteString(line + "\n")
+		}
+	}
+
+	buffer.WriteString("tests := []struct {\nname string\n")
+	for i, field := range fields {
+		fmt.Fprintf(&buffer, "%s %s\n", field, types.TypeString(signature.Params().At(i).Type(), qualifier))
+	}
+	if checkResult {
+		fmt.Fprintf(&buffer, "want %s\n", types.TypeString(signature.Results().At(0).Type(), qualifier))
+	}
+	buffer.WriteString("}{\n")
+
+	for _, testCase := range cases {
+		elements := []string{fmt.Sprintf("name: %q", testCase.Name)}
+		for i, field := range fields {
+			elements = append(elements, fmt.Sprintf("%s: %s", field, testCase.Arguments[i]))
+		}
+		if checkResult {
+			elements = append(elements, "want: "+testCase.Expected)
+		}
+		fmt.Fprintf(&buffer, "{%s},\n", strings.Join(elements, ", "))
+	}
+	buffer.WriteString("}\n\n")
+
+	arguments := make([]string, len(fields))
+	for i, field := range fields {
+		arguments[i] = "tt." + field
+	}
+	call := fmt.Sprintf("%s(%s)", function.Name(), strings.Join(arguments, ", "))
+
+	buffer.WriteString("for _, tt := range tests {\nt.Run(tt.name, func(t *testing.T) {\n")
+	switch {
+	case !checkResult:
+		buffer.WriteString(call + "\n")
+	case isFloat(signature.Results().At(0).Type()):
+		fmt.Fprintf(&buffer, "got := %s\n", call)
+		buffer.WriteString("if got != tt.want && !(math.IsNaN(float64(got)) && math.IsNaN(float64(tt.want))) {\n")
+		fmt.Fprintf(&buffer, "t.Errorf(\"%s() = %%v, want %%v\", got, tt.want)\n}\n", function.Name())
+	default:
+		fmt.Fprintf(&buffer, "got := %s\n", call)
+		buffer.WriteString("if got != tt.want {\n")
+		fmt.Fprintf(&buffer, "t.Errorf(\"%s() = %%v, want %%v\", got, tt.want)\n}\n", function.Name())
+	}
+	buffer.WriteString("})\n}\n}\n")
+
+	return format.Source(buffer.Bytes())
+}
+
+// solve ищет модель условия пути. Сначала решатель пробует считать входные указатели
+// ненулевыми и попарно различными: nil допускается условием пути только там,
+// где он не приводит к панике, а различные объекты дают более естественные тесты
+func (analyser *Analyser) solve(state Interpreter) *Model {
+	var pointers []symbolic.SymbolicExpression
+	for _, param := range analyser.Function.Params {
+		if address, ok := state.CallStack[0].LocalMemory[param.Name()].(*pointer); ok {
+			pointers = append(pointers, address.Base)
+		}
+	}
+
+	var nonNil, distinct []symbolic.SymbolicExpression
+	for i, address := range pointers {
+		nonNil = append(nonNil, symbolic.NewBinaryOperation(address, symbolic.NilRef(), symbolic.NE))
+		for _, other := range pointers[:i] {
+			distinct = append(distinct, symbolic.NewBinaryOperation(address, other, symbolic.NE))
+		}
+	}
+
+	attempts := [][]symbolic.SymbolicExpression{append(nonNil, distinct...), nonNil, nil}
+	for _, assumptions := range attempts {
+		condition := state.PathCondition
+		for _, assumption := range assumptions {
+			condition = conjunction(condition, assumption)
+		}
+		if model, err := analyser.Solver.Solve(condition); err == nil && model != nil {
+			return model
+		}
+	}
+	return nil
+}
+
+// caseGenerator строит один тестовый случай по модели пути
+type caseGenerator struct {
+	analyser *Analyser
+	state    Interpreter
+	model    *Model
+	objects  map[string]string // объект в модели -> имя переменной в тесте
+	testCase TestCase
+}
+
+func (generator *caseGenerator) generate() TestCase {
+	function := generator.analyser.Function
+	for _, param := range function.Params {
+		value := generator.state.CallStack[0].LocalMemory[param.Name()]
+		generator.testCase.Arguments = append(generator.testCase.Arguments, generator.literal(value, param.Type()))
+	}
+
+	if hasCheckableResult(function.Signature) {
+		returnValue := generator.state.CallStack[0].ReturnValue
+		generator.testCase.Expected = generator.literal(returnValue, function.Signature.Results().At(0).Type())
+	}
+	return generator.testCase
+}
+
+// literal возвращает Go-выражение значения value типа tpe в модели
+func (generator *caseGenerator) literal(value symbolic.SymbolicExpression, tpe types.Type) string {
+	switch t := tpe.Underlying().(type) {
+	case *types.Basic:
+		if value == nil {
+			return zeroLiteral(t)
+		}
+		return generator.basicLiteral(value, t)
+	case *types.Struct:
+		ref, ok := value.(*symbolic.Ref)
+		if !ok {
+			return generator.typeString(tpe) + "{}"
+		}
+		return generator.structLiteral(tpe, func(offset int) symbolic.SymbolicExpression {
+			return generator.state.Heap.GetFieldValue(ref, offset)
+		}, 0)
+	case *types.Pointer:
+		address, ok := value.(*pointer)
+		if !ok || !address.Base.Symbolic {
+			return "nil"
+		}
+		return generator.pointerLiteral(address.Base, t.Elem())
+	case *types.Array:
+		if value == nil || value.Type() != symbolic.ArrayType {
+			return generator.typeString(tpe) + "{}"
+		}
+		return generator.arrayLiteral(tpe, value, t.Elem(), t.Len())
+	case *types.Slice:
+		slice, ok := value.(*sliceValue)
+		if !ok || slice.Input == nil {
+			return "nil"
+		}
+		length, err := strconv.ParseInt(generator.basicLiteral(slice.Length, types.Typ[types.Int]), 10, 64)
+		if err != nil || length > maxSliceLiteralLength {
+			return "nil"
+		}
+		return generator.arrayLiteral(tpe, slice.Input, t.Elem(), length)
+	default:
+		return "nil"
+	}
+}
+
+// maxSliceLiteralLength ограничивает длину среза, элементы которого выписываются в тест
+const maxSliceLiteralLength = 64
+
+// arrayLiteral строит составной литерал массива или среза из первых length элементов array
+func (generator *caseGenerator) arrayLiteral(tpe types.Type, array symbolic.SymbolicExpression, elem types.Type, length int64) string {
+	elements := make([]string, length)
+	for i := range elements {
+		element := symbolic.NewArraySelect(array, symbolic.NewBitVecConstant(int64(i), indexType))
+		elements[i] = generator.literal(element, elem)
+	}
+	return fmt.Sprintf("%s{%s}", generator.typeString(tpe), strings.Join(elements, ", "))
+}
+
+// pointerLiteral объявляет объект, на который указывает символьная ссылка.
+// Совпадающие в модели ссылки получают одну и ту же переменную
+func (generator *caseGenerator) pointerLiteral(ref *symbolic.Ref, elem types.Type) string {
+	address := generator.eval(ref)
+	if address == "0" {
+		return "nil"
+	}
+
+	key := elem.String() + "@" + address
+	if name, exists := generator.objects[key]; exists {
+		return name
+	}
+
+	name := fmt.Sprintf("%sObject%d", strings.ReplaceAll(generator.testCase.Name, "_", ""), len(generator.objects)+1)
+	generator.objects[key] = name
+
+	fieldAt := func(offset int) symbolic.SymbolicExpression {
+		return generator.initialField(elem.String(), address, offset)
+	}
+	if _, ok := elem.Underlying().(*types.Struct); ok {
+		generator.testCase.Setup = append(generator.testCase.Setup,
+			fmt.Sprintf("%s := &%s", name, generator.structLiteral(elem, fieldAt, 0)))
+		return name
+	}
+
+	generator.testCase.Setup = append(generator.testCase.Setup,
+		fmt.Sprintf("var %s %s = %s", name, generator.typeString(elem), generator.literal(fieldAt(0), elem)))
+	return "&" + name
+}
+
+// initialField ищет начальное значение поля среди всех ссылок на объект с данным адресом
+func (generator *caseGenerator) initialField(typeName, address string, offset int) symbolic.SymbolicExpression {
+	for _, param := range generator.analyser.Function.Params {
+		paramPointer, ok := param.Type().Underlying().(*types.Pointer)
+		if !ok || paramPointer.Elem().String() != typeName {
+			continue
+		}
+		candidate, ok := generator.state.CallStack[0].LocalMemory[param.Name()].(*pointer)
+		if !ok || generator.eval(candidate.Base) != address {
+			continue
+		}
+		if value := generator.state.Heap.InitialFieldValue(candidate.Base, offset); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// structLiteral строит составной литерал структуры, поля которой лежат в объекте начиная с offset
+func (generator *caseGenerator) structLiteral(tpe types.Type, fieldAt func(int) symbolic.SymbolicExpression, offset int) string {
+	structType := tpe.Underlying().(*types.Struct)
+
+	var elements []string
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		fieldOffset := offset + fieldOffset(structType, i)
+
+		var element string
+		switch field.Type().Underlying().(type) {
+		case *types.Basic:
+			element = generator.literal(fieldAt(fieldOffset), field.Type())
+		case *types.Struct:
+			element = generator.structLiteral(field.Type(), fieldAt, fieldOffset)
+		default:
+			continue
+		}
+		elements = append(elements, fmt.Sprintf("%s: %s", field.Name(), element))
+	}
+	return fmt.Sprintf("%s{%s}", generator.typeString(tpe), strings.Join(elements, ", "))
+}
+
+func (generator *caseGenerator) basicLiteral(value symbolic.SymbolicExpression, tpe *types.Basic) string {
+	if tpe.Info()&types.IsString != 0 {
+		return generator.stringLiteral(value)
+	}
+
+	switch evaluated := generator.model.Eval(value).(type) {
+	case z3.Bool:
+		result, _ := evaluated.AsBool()
+		return strconv.FormatBool(result)
+	case z3.Int:
+		result, _ := evaluated.AsBigInt()
+		return result.String()
+	case z3.BV:
+		var result *big.Int
+		if value.Type().IsSigned() {
+			result, _ = evaluated.AsBigSigned()
+		} else {
+			result, _ = evaluated.AsBigUnsigned()
+		}
+		return result.String()
+	case z3.Float:
+		result, _ := evaluated.AsBigFloat()
+		return generator.floatLiteral(result, tpe.Kind() == types.Float32)
+	default:
+		return zeroLiteral(tpe)
+	}
+}
+
+// stringLiteral возвращает строковую константу, непрозрачное значение которой
+// принимает value в модели. Входные строки не ограничены и остаются пустыми
+func (generator *caseGenerator) stringLiteral(value symbolic.SymbolicExpression) string {
+	if evaluated, ok := generator.model.Eval(value).(z3.Int); ok {
+		if id, literal, exact := evaluated.AsInt64(); literal && exact {
+			if text, exists := generator.analyser.Strings[id]; exists {
+				return strconv.Quote(text)
+			}
+		}
+	}
+	return `""`
+}
+
+func (generator *caseGenerator) floatLiteral(value *big.Float, single bool) string {
+	if value == nil {
+		generator.testCase.UsesMath = true
+		return "math.NaN()"
+	}
+
+	result, _ := value.Float64()
+	switch {
+	case math.IsInf(result, 1):
+		generator.testCase.UsesMath = true
+		return "math.Inf(1)"
+	case math.IsInf(result, -1):
+		generator.testCase.UsesMath = true
+		return "math.Inf(-1)"
+	case result == 0 && math.Signbit(result):
+		generator.testCase.UsesMath = true
+		return "math.Copysign(0, -1)"
+	case single:
+		return strconv.FormatFloat(result, 'g', -1, 32)
+	default:
+		return strconv.FormatFloat(result, 'g', -1, 64)
+	}
+}
+
+// eval возвращает строковое значение выражения в модели
+func (generator *caseGenerator) eval(value symbolic.SymbolicExpression) string {
+	evaluated := generator.model.Eval(value)
+	if evaluated == nil {
+		return ""
+	}
+	return evaluated.String()
+}
+
+func (generator *caseGenerator) typeString(tpe types.Type) string {
+	return types.TypeString(tpe, types.RelativeTo(generator.analyser.Function.Pkg.Pkg))
+}
+
+// parameterFields возвращает имена полей таблицы для параметров функции
+func parameterFields(signature *types.Signature) []string {
+	fields := make([]string, signature.Params().Len())
+	for i := range fields {
+		name := signature.Params().At(i).Name()
+		switch name {
+		case "", "_":
+			name = fmt.Sprintf("arg%d", i)
+		case "name", "want", "tt", "t":
+			name += "Arg"
+		}
+		fields[i] = name
+	}
+	return fields
+}
+
+// hasCheckableResult сообщает, проверяет ли тест результат функции.
+// Проверяются только функции с одним результатом базового типа
+func hasCheckableResult(signature *types.Signature) bool {
+	if signature.Results().Len() != 1 {
+		return false
+	}
+	_, ok := signature.Results().At(0).Type().Underlying().(*types.Basic)
+	return ok
+}
+
+func isFloat(tpe types.Type) bool {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsFloat != 0
+}
+
+func zeroLiteral(tpe *types.Basic) string {
+	switch {
+	case tpe.Info()&types.IsBoolean != 0:
+		return "false"
+	case tpe.Info()&types.IsString != 0:
+		return `""`
+	default:
+		return "0"
+	}
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -23,10 +23,17 @@ type ExpressionTranslator interface {
 	VisitVariable(expr *symbolic.SymbolicVariable) (interface{}, error)
 	VisitIntConstant(expr *symbolic.IntConstant) (interface{}, error)
 	VisitBoolConstant(expr *symbolic.BoolConstant) (interface{}, error)
+	VisitBitVecConstant(expr *symbolic.BitVecConstant) (interface{}, error)
+	VisitFloatConstant(expr *symbolic.FloatConstant) (interface{}, error)
 	VisitRef(expr *symbolic.Ref) (interface{}, error)
 	VisitBinaryOperation(expr *symbolic.BinaryOperation) (interface{}, error)
 	VisitLogicalOperation(expr *symbolic.LogicalOperation) (interface{}, error)
 	VisitUnaryOperation(expr *symbolic.UnaryOperation) (interface{}, error)
+	VisitConversion(expr *symbolic.Conversion) (interface{}, error)
+	VisitIte(expr *symbolic.IteExpression) (interface{}, error)
+	VisitArrayConstant(expr *symbolic.ArrayConstant) (interface{}, error)
+	VisitArraySelect(expr *symbolic.ArraySelect) (interface{}, error)
+	VisitArrayStore(expr *symbolic.ArrayStore) (interface{}, error)
 }
 
 // TranslationError представляет ошибку трансляции
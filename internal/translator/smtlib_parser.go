@@ -0,0 +1,611 @@
+package translator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// SmtLibScript — утверждения скрипта SMT-LIB2, разобранные в символьные выражения.
+// Variables перечислены в порядке объявления
+type SmtLibScript struct {
+	Variables  []*symbolic.SymbolicVariable
+	Assertions []symbolic.SymbolicExpression
+}
+
+// Condition возвращает конъюнкцию всех утверждений скрипта
+func (script *SmtLibScript) Condition() symbolic.SymbolicExpression {
+	switch len(script.Assertions) {
+	case 0:
+		return symbolic.NewBoolConstant(true)
+	case 1:
+		return script.Assertions[0]
+	default:
+		return symbolic.NewLogicalOperation(script.Assertions, symbolic.AND)
+	}
+}
+
+// ParseSmtLib разбирает подмножество SMT-LIB2: команды declare-const и assert над сортами
+// Int, Bool, (_ BitVec n) и (_ FloatingPoint e s), арифметику, сравнения, and/or/not/=>, ite и let.
+// Битовые векторы ширины 8, 16, 32 и 64 становятся знаковыми целыми Go,
+// а беззнаковые операции выражаются через преобразование к беззнаковому типу.
+// Числа с плавающей точкой поддерживаются в форматах float32 и float64 с округлением RNE.
+// Команды set-option, set-logic, set-info, check-sat, get-model и exit пропускаются
+func ParseSmtLib(text string) (*SmtLibScript, error) {
+	parser := &smtLibParser{variables: make(map[string]*symbolic.SymbolicVariable)}
+	if err := parser.parseScript(text); err != nil {
+		return nil, err
+	}
+	return &parser.script, nil
+}
+
+// sexpr — узел s-выражения: атом либо список
+type sexpr struct {
+	atom   string
+	list   []sexpr
+	isList bool
+}
+
+func (e sexpr) String() string {
+	if !e.isList {
+		return e.atom
+	}
+	parts := make([]string, len(e.list))
+	for i, item := range e.list {
+		parts[i] = item.String()
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// head возвращает первый атом списка или пустую строку
+func (e sexpr) head() string {
+	if e.isList && len(e.list) > 0 && !e.list[0].isList {
+		return e.list[0].atom
+	}
+	return ""
+}
+
+type smtLibParser struct {
+	script    SmtLibScript
+	variables map[string]*symbolic.SymbolicVariable
+	bindings  map[string]symbolic.SymbolicExpression // имена, связанные объемлющими let
+}
+
+// smtLibError — ошибка разбора, которой parseTerm прерывает разбор терма
+type smtLibError struct {
+	message string
+}
+
+func (parser *smtLibParser) fail(format string, args ...interface{}) {
+	panic(smtLibError{fmt.Sprintf(format, args...)})
+}
+
+func (parser *smtLibParser) parseScript(text string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if parseErr, ok := recovered.(smtLibError); ok {
+				err = fmt.Errorf("SMT-LIB2: %s", parseErr.message)
+				return
+			}
+			// Конструкторы выражений паникуют при несовпадении типов операндов
+			err = fmt.Errorf("SMT-LIB2: %v", recovered)
+		}
+	}()
+
+	commands, err := readSexprs(text)
+	if err != nil {
+		return err
+	}
+	for _, command := range commands {
+		parser.parseCommand(command)
+	}
+	return nil
+}
+
+func (parser *smtLibParser) parseCommand(command sexpr) {
+	switch command.head() {
+	case "declare-const":
+		if len(command.list) != 3 {
+			parser.fail("неверное объявление %s", command)
+		}
+		parser.declare(command.list[1], command.list[2])
+	case "declare-fun":
+		if len(command.list) != 4 || !command.list[2].isList || len(command.list[2].list) != 0 {
+			parser.fail("поддерживаются только функции без аргументов: %s", command)
+		}
+		parser.declare(command.list[1], command.list[3])
+	case "assert":
+		if len(command.list) != 2 {
+			parser.fail("неверное утверждение %s", command)
+		}
+		assertion := parser.parseTerm(command.list[1])
+		if assertion.Type() != symbolic.BoolType {
+			parser.fail("утверждение %s не булево", command.list[1])
+		}
+		parser.script.Assertions = append(parser.script.Assertions, assertion)
+	case "set-option", "set-logic", "set-info", "check-sat", "get-model", "exit":
+	default:
+		parser.fail("неподдерживаемая команда %s", command)
+	}
+}
+
+func (parser *smtLibParser) declare(name, sort sexpr) {
+	if name.isList {
+		parser.fail("неверное имя константы %s", name)
+	}
+	if _, exists := parser.variables[unquote(name.atom)]; exists {
+		parser.fail("константа %s объявлена повторно", name.atom)
+	}
+	variable := symbolic.NewSymbolicVariable(unquote(name.atom), parser.parseSort(sort))
+	parser.variables[variable.Name] = variable
+	parser.script.Variables = append(parser.script.Variables, variable)
+}
+
+func (parser *smtLibParser) parseSort(sort sexpr) symbolic.ExpressionType {
+	switch {
+	case sort.atom == "Int":
+		return symbolic.IntType
+	case sort.atom == "Bool":
+		return symbolic.BoolType
+	case sort.isList && len(sort.list) == 3 && sort.head() == "_" && sort.list[1].atom == "BitVec":
+		return parser.bitVecType(sort.list[2].atom, true)
+	case sort.atom == "Float32":
+		return symbolic.Float32Type
+	case sort.atom == "Float64":
+		return symbolic.Float64Type
+	case sort.isList && len(sort.list) == 4 && sort.head() == "_" && sort.list[1].atom == "FloatingPoint":
+		return parser.floatType(sort.list[2].atom+" "+sort.list[3].atom, sort)
+	default:
+		parser.fail("неподдерживаемый сорт %s", sort)
+		return 0
+	}
+}
+
+// bitVecType возвращает целый тип Go ширины width
+func (parser *smtLibParser) bitVecType(width string, signed bool) symbolic.ExpressionType {
+	types := map[string][2]symbolic.ExpressionType{
+		"8":  {symbolic.Int8Type, symbolic.Uint8Type},
+		"16": {symbolic.Int16Type, symbolic.Uint16Type},
+		"32": {symbolic.Int32Type, symbolic.Uint32Type},
+		"64": {symbolic.Int64Type, symbolic.Uint64Type},
+	}
+	pair, ok := types[width]
+	if !ok {
+		parser.fail("неподдерживаемая ширина битового вектора %s", width)
+	}
+	if signed {
+		return pair[0]
+	}
+	return pair[1]
+}
+
+// floatType возвращает тип Go с плавающей точкой по ширине экспоненты и мантиссы (с учётом скрытого бита)
+func (parser *smtLibParser) floatType(widths string, sort sexpr) symbolic.ExpressionType {
+	switch widths {
+	case "8 24":
+		return symbolic.Float32Type
+	case "11 53":
+		return symbolic.Float64Type
+	default:
+		parser.fail("неподдерживаемый сорт %s", sort)
+		return 0
+	}
+}
+
+func (parser *smtLibParser) parseTerm(term sexpr) symbolic.SymbolicExpression {
+	if !term.isList {
+		return parser.parseAtom(term.atom)
+	}
+	if len(term.list) == 0 {
+		parser.fail("пустой терм")
+	}
+
+	// Индексированные операторы: ((_ extract i j) x), ((_ sign_extend k) x) и т.п.
+	if term.list[0].isList {
+		return parser.parseIndexed(term.list[0], term.list[1:])
+	}
+	if term.head() == "_" {
+		return parser.parseIndexedConstant(term)
+	}
+	// Отрицательные числа записываются как (- n)
+	if term.head() == "-" && len(term.list) == 2 && isNumeral(term.list[1]) {
+		value, err := strconv.ParseInt("-"+term.list[1].atom, 10, 64)
+		if err != nil {
+			parser.fail("целая константа %s вне диапазона int64", term)
+		}
+		return symbolic.NewIntConstant(value)
+	}
+	if term.head() == "fp" {
+		return parser.floatLiteral(term)
+	}
+	if term.head() == "let" {
+		return parser.parseLet(term)
+	}
+
+	operands := term.list[1:]
+	if smtRoundedOperators[term.head()] {
+		if len(operands) == 0 || operands[0].atom != "RNE" {
+			parser.fail("поддерживается только округление RNE: %s", term)
+		}
+		operands = operands[1:]
+	}
+	args := make([]symbolic.SymbolicExpression, len(operands))
+	for i, arg := range operands {
+		args[i] = parser.parseTerm(arg)
+	}
+	return parser.apply(term.head(), args, term)
+}
+
+// parseLet разбирает (let ((имя терм) ...) тело). Термы связываются параллельно,
+// то есть разбираются в объемлющей области видимости
+func (parser *smtLibParser) parseLet(term sexpr) symbolic.SymbolicExpression {
+	if len(term.list) != 3 || !term.list[1].isList {
+		parser.fail("неверный let %s", term)
+	}
+	bound := make(map[string]symbolic.SymbolicExpression)
+	for _, binding := range term.list[1].list {
+		if !binding.isList || len(binding.list) != 2 || binding.list[0].isList {
+			parser.fail("неверное связывание %s", binding)
+		}
+		bound[unquote(binding.list[0].atom)] = parser.parseTerm(binding.list[1])
+	}
+
+	outer := parser.bindings
+	parser.bindings = make(map[string]symbolic.SymbolicExpression, len(outer)+len(bound))
+	for name, value := range outer {
+		parser.bindings[name] = value
+	}
+	for name, value := range bound {
+		parser.bindings[name] = value
+	}
+	defer func() { parser.bindings = outer }()
+	return parser.parseTerm(term.list[2])
+}
+
+func isNumeral(term sexpr) bool {
+	return !term.isList && term.atom != "" && unicode.IsDigit(rune(term.atom[0]))
+}
+
+// unquote убирает ограничители |...| символа
+func unquote(symbol string) string {
+	if len(symbol) >= 2 && strings.HasPrefix(symbol, "|") && strings.HasSuffix(symbol, "|") {
+		return symbol[1 : len(symbol)-1]
+	}
+	return symbol
+}
+
+func (parser *smtLibParser) parseAtom(atom string) symbolic.SymbolicExpression {
+	switch {
+	case atom == "true" || atom == "false":
+		return symbolic.NewBoolConstant(atom == "true")
+	case strings.HasPrefix(atom, "#b"):
+		return parser.bitVecLiteral(atom[2:], 2, len(atom)-2)
+	case strings.HasPrefix(atom, "#x"):
+		return parser.bitVecLiteral(atom[2:], 16, 4*(len(atom)-2))
+	case isNumeral(sexpr{atom: atom}):
+		value, err := strconv.ParseInt(atom, 10, 64)
+		if err != nil {
+			parser.fail("целая константа %s вне диапазона int64", atom)
+		}
+		return symbolic.NewIntConstant(value)
+	}
+
+	if value, bound := parser.bindings[unquote(atom)]; bound {
+		return value
+	}
+	variable, exists := parser.variables[unquote(atom)]
+	if !exists {
+		parser.fail("константа %s не объявлена", atom)
+	}
+	return variable
+}
+
+// bitVecLiteral разбирает литерал битового вектора в системе счисления base
+func (parser *smtLibParser) bitVecLiteral(digits string, base int, width int) symbolic.SymbolicExpression {
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		parser.fail("неверный литерал битового вектора %s", digits)
+	}
+	return symbolic.NewBitVecConstant(int64(value.Uint64()), parser.bitVecType(strconv.Itoa(width), true))
+}
+
+// floatLiteral разбирает константу (fp знак экспонента мантисса), заданную битовыми литералами
+func (parser *smtLibParser) floatLiteral(term sexpr) symbolic.SymbolicExpression {
+	if len(term.list) != 4 {
+		parser.fail("неверная константа %s", term)
+	}
+	bits := new(big.Int)
+	widths := ""
+	for _, part := range term.list[1:] {
+		var digits string
+		var base, width int
+		switch {
+		case strings.HasPrefix(part.atom, "#b"):
+			digits, base, width = part.atom[2:], 2, len(part.atom)-2
+		case strings.HasPrefix(part.atom, "#x"):
+			digits, base, width = part.atom[2:], 16, 4*(len(part.atom)-2)
+		default:
+			parser.fail("неверная константа %s", term)
+		}
+		value, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			parser.fail("неверная константа %s", term)
+		}
+		bits.Lsh(bits, uint(width)).Or(bits, value)
+		widths += " " + strconv.Itoa(width)
+	}
+
+	switch widths {
+	case " 1 8 23":
+		return symbolic.NewFloatConstant(float64(math.Float32frombits(uint32(bits.Uint64()))), symbolic.Float32Type)
+	case " 1 11 52":
+		return symbolic.NewFloatConstant(math.Float64frombits(bits.Uint64()), symbolic.Float64Type)
+	default:
+		parser.fail("неподдерживаемый формат константы %s", term)
+		return nil
+	}
+}
+
+// parseIndexedConstant разбирает константу (_ bvN w)
+func (parser *smtLibParser) parseIndexedConstant(term sexpr) symbolic.SymbolicExpression {
+	if len(term.list) != 3 || !strings.HasPrefix(term.list[1].atom, "bv") {
+		parser.fail("неподдерживаемый терм %s", term)
+	}
+	value, ok := new(big.Int).SetString(term.list[1].atom[2:], 10)
+	if !ok {
+		parser.fail("неверная константа %s", term)
+	}
+	return symbolic.NewBitVecConstant(int64(value.Uint64()), parser.bitVecType(term.list[2].atom, true))
+}
+
+// parseIndexed разбирает применение индексированного оператора к args
+func (parser *smtLibParser) parseIndexed(operator sexpr, args []sexpr) symbolic.SymbolicExpression {
+	if operator.head() != "_" || len(operator.list) < 3 || len(args) != 1 {
+		parser.fail("неподдерживаемый оператор %s", operator)
+	}
+	name := operator.list[1].atom
+	index, err := strconv.Atoi(operator.list[2].atom)
+	if err != nil {
+		parser.fail("неверный индекс оператора %s", operator)
+	}
+	operand := parser.parseTerm(args[0])
+	width := operand.Type().BitWidth()
+
+	switch {
+	case name == "int2bv" && operand.Type() == symbolic.IntType:
+		return symbolic.NewConversion(operand, parser.bitVecType(strconv.Itoa(index), true))
+	case name == "sign_extend" && width > 0:
+		return symbolic.NewConversion(operand, parser.bitVecType(strconv.Itoa(width+index), true))
+	case name == "zero_extend" && width > 0:
+		unsigned := symbolic.NewConversion(operand, parser.bitVecType(strconv.Itoa(width), false))
+		return symbolic.NewConversion(unsigned, parser.bitVecType(strconv.Itoa(width+index), true))
+	case name == "extract" && width > 0 && len(operator.list) == 4:
+		low, err := strconv.Atoi(operator.list[3].atom)
+		if err != nil || low < 0 || low > index || index >= width {
+			parser.fail("неверные границы %s", operator)
+		}
+		result := parser.bitVecType(strconv.Itoa(index-low+1), true)
+		if low == 0 {
+			return symbolic.NewConversion(operand, result)
+		}
+		// Младшие биты отбрасываются логическим сдвигом, старшие — сужением
+		unsigned := parser.bitVecType(strconv.Itoa(width), false)
+		shifted := symbolic.NewBinaryOperation(
+			symbolic.NewConversion(operand, unsigned),
+			symbolic.NewBitVecConstant(int64(low), unsigned),
+			symbolic.SHR,
+		)
+		return symbolic.NewConversion(shifted, result)
+	default:
+		parser.fail("неподдерживаемый оператор %s", operator)
+		return nil
+	}
+}
+
+var smtBinaryOperators = map[string]symbolic.BinaryOperator{
+	"+": symbolic.ADD, "-": symbolic.SUB, "*": symbolic.MUL, "div": symbolic.DIV, "mod": symbolic.MOD,
+	"<": symbolic.LT, "<=": symbolic.LE, ">": symbolic.GT, ">=": symbolic.GE,
+	"bvadd": symbolic.ADD, "bvsub": symbolic.SUB, "bvmul": symbolic.MUL, "bvsdiv": symbolic.DIV, "bvsrem": symbolic.MOD,
+	"bvslt": symbolic.LT, "bvsle": symbolic.LE, "bvsgt": symbolic.GT, "bvsge": symbolic.GE,
+	"bvand": symbolic.BIT_AND, "bvor": symbolic.BIT_OR, "bvxor": symbolic.BIT_XOR,
+	"bvshl": symbolic.SHL, "bvashr": symbolic.SHR,
+	"fp.add": symbolic.ADD, "fp.sub": symbolic.SUB, "fp.mul": symbolic.MUL, "fp.div": symbolic.DIV,
+	"fp.lt": symbolic.LT, "fp.leq": symbolic.LE, "fp.gt": symbolic.GT, "fp.geq": symbolic.GE, "fp.eq": symbolic.EQ,
+}
+
+// smtRoundedOperators — операции с плавающей точкой, первый аргумент которых — режим округления
+var smtRoundedOperators = map[string]bool{"fp.add": true, "fp.sub": true, "fp.mul": true, "fp.div": true}
+
+// smtUnsignedOperators — операции, сравнивающие или делящие битовые векторы как беззнаковые
+var smtUnsignedOperators = map[string]symbolic.BinaryOperator{
+	"bvudiv": symbolic.DIV, "bvurem": symbolic.MOD, "bvlshr": symbolic.SHR,
+	"bvult": symbolic.LT, "bvule": symbolic.LE, "bvugt": symbolic.GT, "bvuge": symbolic.GE,
+}
+
+// apply строит выражение оператора name над аргументами args
+func (parser *smtLibParser) apply(name string, args []symbolic.SymbolicExpression, term sexpr) symbolic.SymbolicExpression {
+	arity := func(expected int) {
+		if len(args) != expected {
+			parser.fail("оператор %s ожидает %d аргумента: %s", name, expected, term)
+		}
+	}
+	atLeast := func(expected int) {
+		if len(args) < expected {
+			parser.fail("оператор %s ожидает не меньше %d аргументов: %s", name, expected, term)
+		}
+	}
+
+	switch name {
+	case "and", "or":
+		atLeast(2)
+		operator := symbolic.AND
+		if name == "or" {
+			operator = symbolic.OR
+		}
+		return symbolic.NewLogicalOperation(args, operator)
+	case "not":
+		arity(1)
+		return symbolic.NewLogicalOperation(args, symbolic.NOT)
+	case "=>":
+		// Импликация правоассоциативна: (=> a b c) = (=> a (=> b c))
+		atLeast(2)
+		result := args[len(args)-1]
+		for i := len(args) - 2; i >= 0; i-- {
+			result = symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{args[i], result}, symbolic.IMPLIES)
+		}
+		return result
+	case "=":
+		// Цепочка равенств: (= a b c) = (and (= a b) (= b c))
+		atLeast(2)
+		var equalities []symbolic.SymbolicExpression
+		for i := 1; i < len(args); i++ {
+			equalities = append(equalities, symbolic.NewBinaryOperation(args[i-1], args[i], symbolic.EQ))
+		}
+		return conjunction(equalities)
+	case "distinct":
+		// Попарное неравенство всех аргументов
+		atLeast(2)
+		var inequalities []symbolic.SymbolicExpression
+		for i := range args {
+			for j := i + 1; j < len(args); j++ {
+				inequalities = append(inequalities, symbolic.NewBinaryOperation(args[i], args[j], symbolic.NE))
+			}
+		}
+		return conjunction(inequalities)
+	case "ite":
+		arity(3)
+		return symbolic.NewIteExpression(args[0], args[1], args[2])
+	case "-":
+		if len(args) == 1 {
+			return symbolic.NewUnaryOperation(args[0], symbolic.UNARY_MINUS)
+		}
+	case "bvneg", "fp.neg":
+		arity(1)
+		return symbolic.NewUnaryOperation(args[0], symbolic.UNARY_MINUS)
+	case "bvnot":
+		arity(1)
+		return symbolic.NewUnaryOperation(args[0], symbolic.UNARY_COMPLEMENT)
+	case "bv2nat":
+		arity(1)
+		unsigned := symbolic.NewConversion(args[0], parser.bitVecType(strconv.Itoa(args[0].Type().BitWidth()), false))
+		return symbolic.NewConversion(unsigned, symbolic.IntType)
+	}
+
+	if operator, ok := smtUnsignedOperators[name]; ok {
+		arity(2)
+		return parser.unsignedOperation(args[0], args[1], operator)
+	}
+	operator, ok := smtBinaryOperators[name]
+	if !ok {
+		parser.fail("неподдерживаемый оператор %s", name)
+	}
+	switch operator {
+	case symbolic.ADD, symbolic.SUB, symbolic.MUL, symbolic.BIT_AND, symbolic.BIT_OR, symbolic.BIT_XOR:
+		// Левоассоциативные операции: (+ a b c) = (+ (+ a b) c)
+		atLeast(2)
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = symbolic.NewBinaryOperation(result, arg, operator)
+		}
+		return result
+	default:
+		arity(2)
+		return symbolic.NewBinaryOperation(args[0], args[1], operator)
+	}
+}
+
+// conjunction возвращает единственный операнд или конъюнкцию операндов
+func conjunction(operands []symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return symbolic.NewLogicalOperation(operands, symbolic.AND)
+}
+
+// unsignedOperation выполняет операцию над знаковыми битовыми векторами как над беззнаковыми
+func (parser *smtLibParser) unsignedOperation(left, right symbolic.SymbolicExpression, operator symbolic.BinaryOperator) symbolic.SymbolicExpression {
+	signed := left.Type()
+	if !signed.IsBitVector() || right.Type() != signed {
+		parser.fail("беззнаковая операция требует битовые векторы одной ширины")
+	}
+	unsigned := parser.bitVecType(strconv.Itoa(signed.BitWidth()), false)
+	result := symbolic.NewBinaryOperation(
+		symbolic.NewConversion(left, unsigned),
+		symbolic.NewConversion(right, unsigned),
+		operator,
+	)
+	if result.Type() == symbolic.BoolType {
+		return result
+	}
+	return symbolic.NewConversion(result, signed)
+}
+
+// readSexprs разбивает текст на s-выражения верхнего уровня
+func readSexprs(text string) ([]sexpr, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var stack [][]sexpr
+	var top []sexpr
+	for _, token := range tokens {
+		switch token {
+		case "(":
+			stack = append(stack, top)
+			top = nil
+		case ")":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("SMT-LIB2: лишняя закрывающая скобка")
+			}
+			list := sexpr{list: top, isList: true}
+			top = append(stack[len(stack)-1], list)
+			stack = stack[:len(stack)-1]
+		default:
+			top = append(top, sexpr{atom: token})
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("SMT-LIB2: не закрыто скобок: %d", len(stack))
+	}
+	return top, nil
+}
+
+// tokenize разбивает текст на скобки и атомы, пропуская комментарии.
+// Символы в |...| и строки в кавычках остаются одним атомом
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ';':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '|' || c == '"':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("SMT-LIB2: не закрыт %c", c)
+			}
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		default:
+			start := i
+			for i < len(text) && !unicode.IsSpace(rune(text[i])) && !strings.ContainsRune("();|\"", rune(text[i])) {
+				i++
+			}
+			tokens = append(tokens, text[start:i])
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,182 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// TestParseSmtLib тестирует разбор скрипта с объявлениями, комментариями и утверждениями
+func TestParseSmtLib(t *testing.T) {
+	script, err := ParseSmtLib(`
+		; условие пути
+		(set-logic ALL)
+		(declare-const x Int)
+		(declare-const |len(s)| (_ BitVec 64))
+		(declare-fun flag () Bool)
+		(assert (and (> (+ x 1 2) (- 5)) (=> flag (distinct x 0))))
+		(assert (ite flag (bvult |len(s)| #x000000000000000a) (= (bvneg |len(s)|) (_ bv3 64))))
+		(check-sat)
+	`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(script.Variables) != 3 || script.Variables[1].Name != "len(s)" || script.Variables[1].Type() != symbolic.Int64Type {
+		t.Errorf("Unexpected variables %v", script.Variables)
+	}
+	expected := []string{
+		"((((x + 1) + 2) > -5) && (flag => (x != 0)))",
+		"ite(flag, (uint64(len(s)) < uint64(10)), (-len(s) == 3))",
+	}
+	if len(script.Assertions) != len(expected) {
+		t.Fatalf("Expected %d assertions, got %v", len(expected), script.Assertions)
+	}
+	for i, assertion := range script.Assertions {
+		if assertion.String() != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], assertion)
+		}
+	}
+}
+
+// TestParseSmtLibOperators тестирует n-арные сравнения, извлечение битов, let и числа с плавающей точкой
+func TestParseSmtLibOperators(t *testing.T) {
+	script, err := ParseSmtLib(`
+		(declare-const x Int)
+		(declare-const y Int)
+		(declare-const w (_ BitVec 32))
+		(declare-const f (_ FloatingPoint 11 53))
+		(declare-const g Float32)
+		(assert (= x y 3))
+		(assert (distinct x y 0))
+		(assert (= ((_ extract 15 8) w) #x01))
+		(assert (fp.lt (fp.add RNE f (fp #b0 #b01111111111 #x8000000000000)) (fp.neg f)))
+		(assert (fp.eq g (fp #b1 #b10000000 #b00000000000000000000000)))
+		(assert (let ((t (+ x 1)) (x y)) (> t x)))
+	`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []string{
+		"((x == y) && (y == 3))",
+		"((x != y) && (x != 0) && (y != 0))",
+		"(int8((uint32(w) >> 8)) == 1)",
+		"((f + 1.5) < -f)",
+		"(g == -2)",
+		"((x + 1) > y)",
+	}
+	if len(script.Assertions) != len(expected) {
+		t.Fatalf("Expected %d assertions, got %v", len(expected), script.Assertions)
+	}
+	for i, assertion := range script.Assertions {
+		if assertion.String() != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], assertion)
+		}
+	}
+	if script.Variables[3].Type() != symbolic.Float64Type || script.Variables[4].Type() != symbolic.Float32Type {
+		t.Errorf("Unexpected float sorts %v", script.Variables)
+	}
+}
+
+// TestParseSmtLibErrors тестирует сообщения об ошибках вместо паники
+func TestParseSmtLibErrors(t *testing.T) {
+	for _, text := range []string{
+		"(assert (> x 0))",
+		"(declare-const x Real)",
+		"(declare-const x Int) (assert (> x 0)",
+		"(declare-const x Int) (declare-const x Int)",
+		"(declare-const x Int) (assert (and x true))",
+		"(declare-const x Int) (assert (+ x 1))",
+		"(declare-const b (_ BitVec 8)) (assert (bvult b 1))",
+		"(push 1)",
+		"(declare-const w (_ BitVec 32)) (assert (= ((_ extract 3 5) w) #x01))",
+		"(declare-const f Float64) (assert (fp.lt (fp.add RTZ f f) f))",
+		"(declare-const x Int) (assert (and (let ((t x)) (> t 0)) (> t 1)))",
+	} {
+		if _, err := ParseSmtLib(text); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+
+	// Неподдерживаемые конструкции называются в сообщении
+	for text, message := range map[string]string{
+		"(declare-const a (Array Int Int))":            "неподдерживаемый сорт (Array Int Int)",
+		"(declare-const h (_ FloatingPoint 5 11))":     "неподдерживаемый сорт (_ FloatingPoint 5 11)",
+		"(declare-const x Int) (assert (= (abs x) 1))": "неподдерживаемый оператор abs",
+	} {
+		if _, err := ParseSmtLib(text); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected %q for %q, got %v", message, text, err)
+		}
+	}
+}
+
+// TestSmtLibRoundTrip тестирует, что разбор экспортированного скрипта возвращает
+// структурно равное выражение, а для операций без прямого соответствия — равносильное
+func TestSmtLibRoundTrip(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	b := symbolic.NewSymbolicVariable("b", symbolic.Int8Type)
+	w := symbolic.NewSymbolicVariable("len(s)", symbolic.Int64Type)
+	flag := symbolic.NewSymbolicVariable("flag", symbolic.BoolType)
+	u := symbolic.NewSymbolicVariable("u", symbolic.Uint16Type)
+	f := symbolic.NewSymbolicVariable("f", symbolic.Float64Type)
+	g := symbolic.NewSymbolicVariable("g", symbolic.Float32Type)
+
+	binary := symbolic.NewBinaryOperation
+	and := func(operands ...symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewLogicalOperation(operands, symbolic.AND)
+	}
+	not := func(operand symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{operand}, symbolic.NOT)
+	}
+
+	structural := []symbolic.SymbolicExpression{
+		binary(binary(x, symbolic.NewIntConstant(-9223372036854775808), symbolic.SUB), y, symbolic.LE),
+		and(binary(binary(x, y, symbolic.DIV), binary(x, y, symbolic.MOD), symbolic.EQ), not(flag), flag),
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{flag, binary(x, y, symbolic.GT)}, symbolic.OR),
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{flag, binary(symbolic.NewUnaryOperation(x, symbolic.UNARY_MINUS), y, symbolic.GE)}, symbolic.IMPLIES),
+		binary(symbolic.NewIteExpression(flag, x, binary(x, y, symbolic.MUL)), symbolic.NewIntConstant(7), symbolic.LT),
+		binary(binary(b, symbolic.NewBitVecConstant(-128, symbolic.Int8Type), symbolic.MOD), binary(b, b, symbolic.SHR), symbolic.LT),
+		binary(symbolic.NewUnaryOperation(b, symbolic.UNARY_COMPLEMENT), binary(b, symbolic.NewBitVecConstant(3, symbolic.Int8Type), symbolic.BIT_XOR), symbolic.EQ),
+		binary(w, symbolic.NewBitVecConstant(0, symbolic.Int64Type), symbolic.GE),
+		binary(binary(f, symbolic.NewFloatConstant(0.1, symbolic.Float64Type), symbolic.ADD), symbolic.NewUnaryOperation(f, symbolic.UNARY_MINUS), symbolic.LT),
+		binary(binary(g, symbolic.NewFloatConstant(-2.5, symbolic.Float32Type), symbolic.MUL), g, symbolic.EQ),
+	}
+	equivalent := []symbolic.SymbolicExpression{
+		binary(x, y, symbolic.NE),
+		binary(u, symbolic.NewBitVecConstant(60000, symbolic.Uint16Type), symbolic.GT),
+		binary(binary(u, symbolic.NewBitVecConstant(7, symbolic.Uint16Type), symbolic.DIV), u, symbolic.LE),
+		binary(symbolic.NewConversion(b, symbolic.Int32Type), symbolic.NewConversion(u, symbolic.Int32Type), symbolic.LT),
+		binary(symbolic.NewConversion(u, symbolic.IntType), x, symbolic.EQ),
+		binary(symbolic.NewConversion(w, symbolic.Int16Type), symbolic.NewConversion(x, symbolic.Int16Type), symbolic.NE),
+	}
+
+	roundTrip := func(t *testing.T, expr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		t.Helper()
+		text, err := NewSmtLibTranslator().Script(expr)
+		if err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+		script, err := ParseSmtLib(text)
+		if err != nil {
+			t.Fatalf("Parse of\n%s\nfailed: %v", text, err)
+		}
+		return script.Condition()
+	}
+
+	for _, expr := range structural {
+		if parsed := roundTrip(t, expr); !parsed.Equals(expr) {
+			t.Errorf("Round trip changed %s to %s", expr, parsed)
+		}
+	}
+
+	zt := NewZ3Translator()
+	for _, expr := range equivalent {
+		parsed := roundTrip(t, expr)
+		if !isValid(t, zt, binary(parsed, expr, symbolic.EQ)) {
+			t.Errorf("Round trip of %s gave non-equivalent %s", expr, parsed)
+		}
+	}
+}
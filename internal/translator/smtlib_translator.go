@@ -0,0 +1,469 @@
+package translator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// SmtLibTranslator транслирует символьные выражения в термы SMT-LIB2.
+// Семантика термов совпадает с трансляцией Z3Translator, поэтому полученные скрипты
+// можно решать внешним решателем вместо Z3 из анализатора
+type SmtLibTranslator struct {
+	declarations []string
+	declared     map[string]bool
+	err          error
+}
+
+// NewSmtLibTranslator создаёт транслятор в SMT-LIB2
+func NewSmtLibTranslator() *SmtLibTranslator {
+	return &SmtLibTranslator{declared: make(map[string]bool)}
+}
+
+// GetContext возвращает объявления констант, встреченных при трансляции
+func (st *SmtLibTranslator) GetContext() interface{} {
+	return st.declarations
+}
+
+// Reset забывает объявленные константы
+func (st *SmtLibTranslator) Reset() {
+	st.declarations = nil
+	st.declared = make(map[string]bool)
+	st.err = nil
+}
+
+// TranslateExpression возвращает терм SMT-LIB2 выражения в виде строки
+func (st *SmtLibTranslator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
+	st.err = nil
+	term := st.translate(expr)
+	if st.err != nil {
+		return nil, st.err
+	}
+	return term, nil
+}
+
+// Script возвращает скрипт SMT-LIB2, проверяющий выполнимость condition:
+// объявления всех переменных и ссылок, утверждение и запрос модели
+func (st *SmtLibTranslator) Script(condition symbolic.SymbolicExpression) (string, error) {
+	if condition.Type() != symbolic.BoolType {
+		return "", NewTranslationError("утверждение должно быть булевым", condition)
+	}
+	term, err := st.TranslateExpression(condition)
+	if err != nil {
+		return "", err
+	}
+
+	var script strings.Builder
+	script.WriteString("(set-option :produce-models true)\n")
+	for _, declaration := range st.declarations {
+		script.WriteString(declaration + "\n")
+	}
+	fmt.Fprintf(&script, "(assert %s)\n", term)
+	script.WriteString("(check-sat)\n(get-model)\n")
+	return script.String(), nil
+}
+
+func (st *SmtLibTranslator) translate(expr symbolic.SymbolicExpression) string {
+	if result, ok := expr.Accept(st).(string); ok {
+		return result
+	}
+	return ""
+}
+
+// fail запоминает первую ошибку трансляции
+func (st *SmtLibTranslator) fail(message string, expr symbolic.SymbolicExpression) interface{} {
+	if st.err == nil {
+		st.err = NewTranslationError(message, expr)
+	}
+	return nil
+}
+
+// declare объявляет константу name сорта sort при первом обращении
+func (st *SmtLibTranslator) declare(name, sort string) string {
+	name = Symbol(name)
+	if !st.declared[name] {
+		st.declared[name] = true
+		st.declarations = append(st.declarations, fmt.Sprintf("(declare-const %s %s)", name, sort))
+	}
+	return name
+}
+
+var simpleSymbol = regexp.MustCompile(`^[a-zA-Z~!@$%^&*_+=<>.?/-][a-zA-Z0-9~!@$%^&*_+=<>.?/-]*$`)
+
+// Symbol возвращает имя в синтаксисе символа SMT-LIB2, заключая его в |...| при необходимости
+func Symbol(name string) string {
+	if simpleSymbol.MatchString(name) {
+		return name
+	}
+	return "|" + name + "|"
+}
+
+// SmtLibSort возвращает сорт SMT-LIB2 скалярного типа выражения
+func SmtLibSort(exprType symbolic.ExpressionType) (string, bool) {
+	switch {
+	case exprType == symbolic.IntType, exprType == symbolic.RefType:
+		return "Int", true
+	case exprType == symbolic.BoolType:
+		return "Bool", true
+	case exprType.IsBitVector():
+		return fmt.Sprintf("(_ BitVec %d)", exprType.BitWidth()), true
+	case exprType == symbolic.Float32Type:
+		return "(_ FloatingPoint 8 24)", true
+	case exprType == symbolic.Float64Type:
+		return "(_ FloatingPoint 11 53)", true
+	default:
+		return "", false
+	}
+}
+
+func arraySort(indexType, elemType symbolic.ExpressionType) (string, bool) {
+	index, ok1 := SmtLibSort(indexType)
+	elem, ok2 := SmtLibSort(elemType)
+	return fmt.Sprintf("(Array %s %s)", index, elem), ok1 && ok2
+}
+
+// floatIndices возвращает ширины экспоненты и мантиссы, как в floatSort
+func floatIndices(exprType symbolic.ExpressionType) string {
+	if exprType == symbolic.Float32Type {
+		return "8 24"
+	}
+	return "11 53"
+}
+
+func (st *SmtLibTranslator) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
+	var sort string
+	var ok bool
+	if expr.Type() == symbolic.ArrayType {
+		sort, ok = arraySort(expr.IndexType, expr.ElemType)
+	} else {
+		sort, ok = SmtLibSort(expr.Type())
+	}
+	if !ok {
+		return st.fail(fmt.Sprintf("неподдерживаемый тип переменной %s", expr.Name), expr)
+	}
+	return st.declare(expr.Name, sort)
+}
+
+func (st *SmtLibTranslator) VisitIntConstant(expr *symbolic.IntConstant) interface{} {
+	return intLiteral(expr.Value)
+}
+
+func intLiteral(value int64) string {
+	if value < 0 {
+		return fmt.Sprintf("(- %s)", strconv.FormatUint(uint64(-value), 10))
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+func (st *SmtLibTranslator) VisitBoolConstant(expr *symbolic.BoolConstant) interface{} {
+	return strconv.FormatBool(expr.Value)
+}
+
+func (st *SmtLibTranslator) VisitBitVecConstant(expr *symbolic.BitVecConstant) interface{} {
+	width := expr.Type().BitWidth()
+	value := uint64(expr.Value)
+	if width < 64 {
+		value &= 1<<uint(width) - 1
+	}
+	return fmt.Sprintf("(_ bv%d %d)", value, width)
+}
+
+// VisitFloatConstant записывает константу точно, через битовое представление
+func (st *SmtLibTranslator) VisitFloatConstant(expr *symbolic.FloatConstant) interface{} {
+	if expr.Type() == symbolic.Float32Type {
+		bits := math.Float32bits(float32(expr.Value))
+		return fmt.Sprintf("(fp #b%01b #b%08b #b%023b)", bits>>31, bits>>23&0xff, bits&(1<<23-1))
+	}
+	bits := math.Float64bits(expr.Value)
+	return fmt.Sprintf("(fp #b%01b #b%011b #b%052b)", bits>>63, bits>>52&0x7ff, bits&(1<<52-1))
+}
+
+func (st *SmtLibTranslator) VisitRef(expr *symbolic.Ref) interface{} {
+	if expr.Symbolic {
+		return st.declare(expr.String(), "Int")
+	}
+	return intLiteral(int64(expr.ID))
+}
+
+func (st *SmtLibTranslator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
+	left := st.translate(expr.Left)
+	right := st.translate(expr.Right)
+	if st.err != nil {
+		return nil
+	}
+	leftType := expr.Left.Type()
+
+	switch expr.Operator {
+	case symbolic.EQ:
+		return smtEqual(left, right, leftType)
+	case symbolic.NE:
+		return fmt.Sprintf("(not %s)", smtEqual(left, right, leftType))
+	case symbolic.BIT_AND, symbolic.BIT_OR, symbolic.BIT_XOR, symbolic.BIT_AND_NOT:
+		return smtBitwise(expr.Operator, left, right, leftType)
+	case symbolic.SHL, symbolic.SHR:
+		return smtShift(expr.Operator, left, leftType, right, expr.Right.Type())
+	}
+
+	var operator string
+	switch {
+	case leftType.IsFloat():
+		// Арифметика с плавающей точкой округляет к ближайшему чётному
+		if operator, ok := floatArithmetic[expr.Operator]; ok {
+			return fmt.Sprintf("(%s RNE %s %s)", operator, left, right)
+		}
+		operator = floatComparisons[expr.Operator]
+	case leftType.IsBitVector() && leftType.IsSigned():
+		operator = signedBitVecOperators[expr.Operator]
+	case leftType.IsBitVector():
+		operator = unsignedBitVecOperators[expr.Operator]
+	default:
+		operator = intOperators[expr.Operator]
+	}
+	if operator == "" {
+		return st.fail(fmt.Sprintf("неподдерживаемый оператор %s", expr.Operator), expr)
+	}
+	return fmt.Sprintf("(%s %s %s)", operator, left, right)
+}
+
+var intOperators = map[symbolic.BinaryOperator]string{
+	symbolic.ADD: "+", symbolic.SUB: "-", symbolic.MUL: "*", symbolic.DIV: "div", symbolic.MOD: "mod",
+	symbolic.LT: "<", symbolic.LE: "<=", symbolic.GT: ">", symbolic.GE: ">=",
+}
+
+var signedBitVecOperators = map[symbolic.BinaryOperator]string{
+	symbolic.ADD: "bvadd", symbolic.SUB: "bvsub", symbolic.MUL: "bvmul", symbolic.DIV: "bvsdiv", symbolic.MOD: "bvsrem",
+	symbolic.LT: "bvslt", symbolic.LE: "bvsle", symbolic.GT: "bvsgt", symbolic.GE: "bvsge",
+}
+
+var unsignedBitVecOperators = map[symbolic.BinaryOperator]string{
+	symbolic.ADD: "bvadd", symbolic.SUB: "bvsub", symbolic.MUL: "bvmul", symbolic.DIV: "bvudiv", symbolic.MOD: "bvurem",
+	symbolic.LT: "bvult", symbolic.LE: "bvule", symbolic.GT: "bvugt", symbolic.GE: "bvuge",
+}
+
+var floatArithmetic = map[symbolic.BinaryOperator]string{
+	symbolic.ADD: "fp.add", symbolic.SUB: "fp.sub", symbolic.MUL: "fp.mul", symbolic.DIV: "fp.div",
+}
+
+var floatComparisons = map[symbolic.BinaryOperator]string{
+	symbolic.LT: "fp.lt", symbolic.LE: "fp.leq", symbolic.GT: "fp.gt", symbolic.GE: "fp.geq",
+}
+
+// smtEqual строит равенство; для чисел с плавающей точкой — равенство IEEE-754, как в Go
+func smtEqual(left, right string, exprType symbolic.ExpressionType) string {
+	if exprType.IsFloat() {
+		return fmt.Sprintf("(fp.eq %s %s)", left, right)
+	}
+	return fmt.Sprintf("(= %s %s)", left, right)
+}
+
+// smtBitwise строит побитовую операцию; неограниченные целые представляются 64-битными векторами
+func smtBitwise(op symbolic.BinaryOperator, left, right string, exprType symbolic.ExpressionType) string {
+	l, _ := smtToBV(left, exprType)
+	r, _ := smtToBV(right, exprType)
+
+	var result string
+	switch op {
+	case symbolic.BIT_AND:
+		result = fmt.Sprintf("(bvand %s %s)", l, r)
+	case symbolic.BIT_OR:
+		result = fmt.Sprintf("(bvor %s %s)", l, r)
+	case symbolic.BIT_XOR:
+		result = fmt.Sprintf("(bvxor %s %s)", l, r)
+	case symbolic.BIT_AND_NOT:
+		result = fmt.Sprintf("(bvand %s (bvnot %s))", l, r)
+	}
+	return smtFromBV(result, exprType)
+}
+
+// smtShift строит сдвиг по правилам Go так же, как translateShift
+func smtShift(op symbolic.BinaryOperator, left string, leftType symbolic.ExpressionType, count string, countType symbolic.ExpressionType) string {
+	l, leftWidth := smtToBV(left, leftType)
+	c, countWidth := smtToBV(count, countType)
+
+	width := leftWidth
+	if countWidth > width {
+		width = countWidth
+	}
+	signed := leftType.IsSigned()
+	l = smtResizeBV(l, leftWidth, width, signed)
+	c = smtResizeBV(c, countWidth, width, false)
+
+	operator := "bvlshr"
+	switch {
+	case op == symbolic.SHL:
+		operator = "bvshl"
+	case signed:
+		operator = "bvashr"
+	}
+	result := fmt.Sprintf("(%s %s %s)", operator, l, c)
+	return smtFromBV(smtResizeBV(result, width, leftWidth, signed), leftType)
+}
+
+func smtToBV(term string, exprType symbolic.ExpressionType) (string, int) {
+	if exprType == symbolic.IntType {
+		return fmt.Sprintf("((_ int2bv 64) %s)", term), 64
+	}
+	return term, exprType.BitWidth()
+}
+
+func smtFromBV(term string, exprType symbolic.ExpressionType) string {
+	if exprType == symbolic.IntType {
+		return signedToInt(term, 64)
+	}
+	return term
+}
+
+// signedToInt интерпретирует битовый вектор ширины width как целое в дополнительном коде.
+// Операнд связывается через let один раз, иначе вложенные преобразования
+// повторяли бы его текст и размер терма рос бы экспоненциально
+func signedToInt(term string, width int) string {
+	return fmt.Sprintf("(let ((t %s)) (ite (bvslt t (_ bv0 %d)) (- (bv2nat t) %s) (bv2nat t)))",
+		term, width, new(big.Int).Lsh(big.NewInt(1), uint(width)))
+}
+
+func smtResizeBV(term string, from, to int, signed bool) string {
+	switch {
+	case to < from:
+		return fmt.Sprintf("((_ extract %d 0) %s)", to-1, term)
+	case to > from && signed:
+		return fmt.Sprintf("((_ sign_extend %d) %s)", to-from, term)
+	case to > from:
+		return fmt.Sprintf("((_ zero_extend %d) %s)", to-from, term)
+	default:
+		return term
+	}
+}
+
+func (st *SmtLibTranslator) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
+	operands := make([]string, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = st.translate(operand)
+	}
+	if st.err != nil {
+		return nil
+	}
+
+	var operator string
+	switch expr.Operator {
+	case symbolic.AND:
+		operator = "and"
+	case symbolic.OR:
+		operator = "or"
+	case symbolic.NOT:
+		operator = "not"
+	case symbolic.IMPLIES:
+		operator = "=>"
+	default:
+		return st.fail(fmt.Sprintf("неизвестный логический оператор %s", expr.Operator), expr)
+	}
+	return fmt.Sprintf("(%s %s)", operator, strings.Join(operands, " "))
+}
+
+func (st *SmtLibTranslator) VisitUnaryOperation(expr *symbolic.UnaryOperation) interface{} {
+	operand := st.translate(expr.Operand)
+	if st.err != nil {
+		return nil
+	}
+
+	operandType := expr.Operand.Type()
+	switch expr.Operator {
+	case symbolic.UNARY_MINUS:
+		switch {
+		case operandType.IsBitVector():
+			return fmt.Sprintf("(bvneg %s)", operand)
+		case operandType.IsFloat():
+			return fmt.Sprintf("(fp.neg %s)", operand)
+		default:
+			return fmt.Sprintf("(- %s)", operand)
+		}
+	case symbolic.UNARY_COMPLEMENT:
+		bv, _ := smtToBV(operand, operandType)
+		return smtFromBV(fmt.Sprintf("(bvnot %s)", bv), operandType)
+	case symbolic.UNARY_NOT:
+		return fmt.Sprintf("(not %s)", operand)
+	default:
+		return st.fail(fmt.Sprintf("неизвестный унарный оператор %s", expr.Operator), expr)
+	}
+}
+
+func (st *SmtLibTranslator) VisitIte(expr *symbolic.IteExpression) interface{} {
+	cond := st.translate(expr.Cond)
+	then := st.translate(expr.Then)
+	els := st.translate(expr.Else)
+	if st.err != nil {
+		return nil
+	}
+	return fmt.Sprintf("(ite %s %s %s)", cond, then, els)
+}
+
+func (st *SmtLibTranslator) VisitArrayConstant(expr *symbolic.ArrayConstant) interface{} {
+	value := st.translate(expr.Default)
+	sort, ok := arraySort(expr.IndexType, expr.ElemType)
+	if !ok {
+		return st.fail("неподдерживаемые сорта массива", expr)
+	}
+	if st.err != nil {
+		return nil
+	}
+	return fmt.Sprintf("((as const %s) %s)", sort, value)
+}
+
+func (st *SmtLibTranslator) VisitArraySelect(expr *symbolic.ArraySelect) interface{} {
+	array := st.translate(expr.Array)
+	index := st.translate(expr.Index)
+	if st.err != nil {
+		return nil
+	}
+	return fmt.Sprintf("(select %s %s)", array, index)
+}
+
+func (st *SmtLibTranslator) VisitArrayStore(expr *symbolic.ArrayStore) interface{} {
+	array := st.translate(expr.Array)
+	index := st.translate(expr.Index)
+	value := st.translate(expr.Value)
+	if st.err != nil {
+		return nil
+	}
+	return fmt.Sprintf("(store %s %s %s)", array, index, value)
+}
+
+// VisitConversion строит преобразование между числовыми типами так же, как Z3Translator
+func (st *SmtLibTranslator) VisitConversion(expr *symbolic.Conversion) interface{} {
+	operand := st.translate(expr.Operand)
+	if st.err != nil {
+		return nil
+	}
+
+	from, to := expr.Operand.Type(), expr.Type()
+	switch {
+	case from == to:
+		return operand
+	case from.IsFloat() && to.IsFloat():
+		return fmt.Sprintf("((_ to_fp %s) RNE %s)", floatIndices(to), operand)
+	case to.IsFloat() && from == symbolic.IntType:
+		return fmt.Sprintf("((_ to_fp %s) RNE (to_real %s))", floatIndices(to), operand)
+	case to.IsFloat() && from.IsSigned():
+		return fmt.Sprintf("((_ to_fp %s) RNE %s)", floatIndices(to), operand)
+	case to.IsFloat():
+		return fmt.Sprintf("((_ to_fp_unsigned %s) RNE %s)", floatIndices(to), operand)
+	case from.IsFloat() && to == symbolic.IntType:
+		return fmt.Sprintf("(to_int (fp.to_real (fp.roundToIntegral RTZ %s)))", operand)
+	case from.IsFloat() && to.IsSigned():
+		return fmt.Sprintf("((_ fp.to_sbv %d) RTZ %s)", to.BitWidth(), operand)
+	case from.IsFloat():
+		return fmt.Sprintf("((_ fp.to_ubv %d) RTZ %s)", to.BitWidth(), operand)
+	case from == symbolic.IntType:
+		return fmt.Sprintf("((_ int2bv %d) %s)", to.BitWidth(), operand)
+	case to == symbolic.IntType && from.IsSigned():
+		return signedToInt(operand, from.BitWidth())
+	case to == symbolic.IntType:
+		return fmt.Sprintf("(bv2nat %s)", operand)
+	default:
+		return smtResizeBV(operand, from.BitWidth(), to.BitWidth(), from.IsSigned())
+	}
+}
@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// TestSmtLibTerms тестирует термы SMT-LIB2 для операций с семантикой Go
+func TestSmtLibTerms(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	x8 := symbolic.NewSymbolicVariable("x8", symbolic.Int8Type)
+	u8 := symbolic.NewSymbolicVariable("u8", symbolic.Uint8Type)
+	f := symbolic.NewSymbolicVariable("f", symbolic.Float32Type)
+	length := symbolic.NewSymbolicVariable("len(s)", symbolic.Int64Type)
+	array := symbolic.NewArrayVariable("s", symbolic.Int64Type, symbolic.BoolType)
+
+	tests := []struct {
+		name     string
+		expr     symbolic.SymbolicExpression
+		expected string
+	}{
+		{"negative int", symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(-3), symbolic.MOD), "(mod x (- 3))"},
+		{"signed division", symbolic.NewBinaryOperation(x8, symbolic.NewBitVecConstant(-1, symbolic.Int8Type), symbolic.DIV), "(bvsdiv x8 (_ bv255 8))"},
+		{"unsigned comparison", symbolic.NewBinaryOperation(u8, symbolic.NewBitVecConstant(200, symbolic.Uint8Type), symbolic.LT), "(bvult u8 (_ bv200 8))"},
+		{"float arithmetic", symbolic.NewBinaryOperation(f, symbolic.NewFloatConstant(1.5, symbolic.Float32Type), symbolic.ADD), "(fp.add RNE f (fp #b0 #b01111111 #b10000000000000000000000))"},
+		{"float equality", symbolic.NewBinaryOperation(f, f, symbolic.NE), "(not (fp.eq f f))"},
+		{"quoted symbol", symbolic.NewBinaryOperation(length, symbolic.NewBitVecConstant(0, symbolic.Int64Type), symbolic.GE), "(bvsge |len(s)| (_ bv0 64))"},
+		{"shift", symbolic.NewBinaryOperation(x8, u8, symbolic.SHR), "(bvashr x8 u8)"},
+		{"widening", symbolic.NewConversion(x8, symbolic.Int32Type), "((_ sign_extend 24) x8)"},
+		{"narrowing", symbolic.NewConversion(x, symbolic.Uint8Type), "((_ int2bv 8) x)"},
+		{"unsigned to int", symbolic.NewConversion(u8, symbolic.IntType), "(bv2nat u8)"},
+		{"signed to int", symbolic.NewConversion(x8, symbolic.IntType), "(let ((t x8)) (ite (bvslt t (_ bv0 8)) (- (bv2nat t) 256) (bv2nat t)))"},
+		{"float truncation", symbolic.NewConversion(f, symbolic.Int8Type), "((_ fp.to_sbv 8) RTZ f)"},
+		{"implication", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			symbolic.NewArraySelect(array, symbolic.NewBitVecConstant(1, symbolic.Int64Type)),
+			symbolic.NewBoolConstant(false),
+		}, symbolic.IMPLIES), "(=> (select s (_ bv1 64)) false)"},
+		{"array constant", symbolic.NewArrayConstant(symbolic.Int64Type, symbolic.NewBoolConstant(true)), "((as const (Array (_ BitVec 64) Bool)) true)"},
+		{"symbolic ref", symbolic.NewBinaryOperation(symbolic.NewSymbolicRef(2, symbolic.RefType), symbolic.NilRef(), symbolic.EQ), "(= ref_2 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := NewSmtLibTranslator().TranslateExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("Translation failed: %v", err)
+			}
+			if term != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, term)
+			}
+		})
+	}
+}
+
+// TestSmtLibTermSize тестирует, что размер терма вложенных битовых операций над Int
+// растёт линейно: каждый цикл операций добавляет к терму одно и то же число символов
+func TestSmtLibTermSize(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	operators := []symbolic.BinaryOperator{symbolic.BIT_AND, symbolic.SHL, symbolic.BIT_XOR, symbolic.SHR}
+
+	expr := symbolic.SymbolicExpression(x)
+	var sizes []int
+	for i := 0; i < 12; i++ {
+		expr = symbolic.NewBinaryOperation(expr, y, operators[i%len(operators)])
+		term, err := NewSmtLibTranslator().TranslateExpression(expr)
+		if err != nil {
+			t.Fatalf("Translation failed: %v", err)
+		}
+		sizes = append(sizes, len(term.(string)))
+	}
+
+	cycle := len(operators)
+	for i := cycle + 1; i < len(sizes); i++ {
+		if sizes[i]-sizes[i-cycle] != sizes[cycle]-sizes[0] {
+			t.Fatalf("Term size grows non-linearly: %v", sizes)
+		}
+	}
+}
+
+// TestSmtLibScript тестирует объявления и команды скрипта
+func TestSmtLibScript(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	flag := symbolic.NewSymbolicVariable("flag", symbolic.BoolType)
+	condition := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.GT),
+		flag,
+		symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(10), symbolic.LT),
+	}, symbolic.AND)
+
+	script, err := NewSmtLibTranslator().Script(condition)
+	if err != nil {
+		t.Fatalf("Script failed: %v", err)
+	}
+	expected := `(set-option :produce-models true)
+(declare-const x Int)
+(declare-const flag Bool)
+(assert (and (> x 0) flag (< x 10)))
+(check-sat)
+(get-model)
+`
+	if script != expected {
+		t.Errorf("Expected script:\n%s\ngot:\n%s", expected, script)
+	}
+
+	if _, err := NewSmtLibTranslator().Script(x); err == nil {
+		t.Errorf("Expected error for non-boolean assertion")
+	}
+}
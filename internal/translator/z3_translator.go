@@ -14,17 +14,40 @@ type Z3Translator struct {
 	ctx    *z3.Context
 	config *z3.Config
 	vars   map[string]z3.Value // Кэш переменных
+
+	// Кэш трансляции всех узлов: структурно равные подвыражения разных
+	// условий пути транслируются один раз
+	memo  map[uint64][]memoEntry
+	stats CacheStats
+
+	// bind заменяет переменные значениями при трансляции через TranslateBound
+	bind func(name string, variable z3.Value) z3.Value
+}
+
+// memoEntry — транслированное выражение в корзине кэша с общим хешем
+type memoEntry struct {
+	expr  symbolic.SymbolicExpression
+	value interface{}
+}
+
+// CacheStats — статистика обращений к кэшу трансляции
+type CacheStats struct {
+	Hits   int
+	Misses int
 }
 
 // NewZ3Translator создаёт новый экземпляр Z3 транслятора
 func NewZ3Translator() *Z3Translator {
 	config := &z3.Config{}
 	ctx := z3.NewContext(config)
+	// Go выполняет операции с плавающей точкой с округлением к ближайшему чётному
+	ctx.SetRoundingMode(z3.RoundToNearestEven)
 
 	return &Z3Translator{
 		ctx:    ctx,
 		config: config,
 		vars:   make(map[string]z3.Value),
+		memo:   make(map[uint64][]memoEntry),
 	}
 }
 
@@ -33,9 +56,16 @@ func (zt *Z3Translator) GetContext() interface{} {
 	return zt.ctx
 }
 
-// Reset сбрасывает состояние транслятора
+// Reset сбрасывает состояние транслятора вместе с кэшем трансляции и его статистикой
 func (zt *Z3Translator) Reset() {
 	zt.vars = make(map[string]z3.Value)
+	zt.memo = make(map[uint64][]memoEntry)
+	zt.stats = CacheStats{}
+}
+
+// Stats возвращает статистику кэша трансляции с последнего сброса
+func (zt *Z3Translator) Stats() CacheStats {
+	return zt.stats
 }
 
 // Close освобождает ресурсы
@@ -45,13 +75,46 @@ func (zt *Z3Translator) Close() {
 
 // TranslateExpression транслирует символьное выражение в Z3
 func (zt *Z3Translator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
-	result := expr.Accept(zt)
+	result := zt.translate(expr)
 	if result == nil {
 		return nil, fmt.Errorf("трансляция вернула nil")
 	}
 	return result, nil
 }
 
+// TranslateBound транслирует выражение, заменяя переменные значениями bind.
+// Переменные, для которых bind возвращает nil, остаются свободными.
+// Результат зависит от bind, поэтому кэш трансляции не используется
+func (zt *Z3Translator) TranslateBound(expr symbolic.SymbolicExpression, bind func(name string, variable z3.Value) z3.Value) (interface{}, error) {
+	bound := &Z3Translator{
+		ctx:    zt.ctx,
+		config: zt.config,
+		vars:   make(map[string]z3.Value),
+		memo:   make(map[uint64][]memoEntry),
+		bind:   bind,
+	}
+	return bound.TranslateExpression(expr)
+}
+
+// translate транслирует выражение, используя ранее полученный результат
+// для структурно равного выражения. Неудачные трансляции не запоминаются
+func (zt *Z3Translator) translate(expr symbolic.SymbolicExpression) interface{} {
+	hash := expr.Hash()
+	for _, entry := range zt.memo[hash] {
+		if entry.expr.Equals(expr) {
+			zt.stats.Hits++
+			return entry.value
+		}
+	}
+
+	zt.stats.Misses++
+	result := expr.Accept(zt)
+	if result != nil {
+		zt.memo[hash] = append(zt.memo[hash], memoEntry{expr: expr, value: result})
+	}
+	return result
+}
+
 // VisitVariable транслирует символьную переменную в Z3
 func (zt *Z3Translator) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
 	// Проверить, есть ли переменная в кэше
@@ -66,13 +129,36 @@ func (zt *Z3Translator) VisitVariable(expr *symbolic.SymbolicVariable) interface
 		z3Var = zt.ctx.IntConst(expr.Name)
 	case symbolic.BoolType:
 		z3Var = zt.ctx.BoolConst(expr.Name)
+	case symbolic.Int8Type, symbolic.Int16Type, symbolic.Int32Type, symbolic.Int64Type,
+		symbolic.Uint8Type, symbolic.Uint16Type, symbolic.Uint32Type, symbolic.Uint64Type:
+		z3Var = zt.ctx.BVConst(expr.Name, expr.Type().BitWidth())
+	case symbolic.Float32Type, symbolic.Float64Type:
+		z3Var = zt.ctx.Const(expr.Name, zt.floatSort(expr.Type()))
+	case symbolic.ArrayType:
+		indexSort, ok1 := zt.sort(expr.IndexType)
+		elemSort, ok2 := zt.sort(expr.ElemType)
+		if !ok1 || !ok2 {
+			fmt.Printf("Warning: неподдерживаемые сорта массива: %v -> %v\n", expr.IndexType, expr.ElemType)
+			return nil
+		}
+		z3Var = zt.ctx.Const(expr.Name, zt.ctx.ArraySort(indexSort, elemSort))
 	default:
 		fmt.Printf("Warning: неподдерживаемый тип переменной: %v\n", expr.Type())
 		return nil
 	}
 
 	// Добавить в кэш и вернуть
-	zt.vars[expr.Name] = z3Var
+	return zt.variable(expr.Name, z3Var)
+}
+
+// variable запоминает переменную name, заменив её значением bind, если оно задано
+func (zt *Z3Translator) variable(name string, z3Var z3.Value) z3.Value {
+	if zt.bind != nil {
+		if value := zt.bind(name, z3Var); value != nil {
+			z3Var = value
+		}
+	}
+	zt.vars[name] = z3Var
 	return z3Var
 }
 
@@ -88,8 +174,54 @@ func (zt *Z3Translator) VisitBoolConstant(expr *symbolic.BoolConstant) interface
 	return zt.ctx.FromBool(expr.Value)
 }
 
+// VisitBitVecConstant транслирует константу фиксированной ширины в битовый вектор Z3
+func (zt *Z3Translator) VisitBitVecConstant(expr *symbolic.BitVecConstant) interface{} {
+	return zt.ctx.FromInt(expr.Value, zt.ctx.BVSort(expr.Type().BitWidth()))
+}
+
+// VisitFloatConstant транслирует константу с плавающей точкой в Z3
+func (zt *Z3Translator) VisitFloatConstant(expr *symbolic.FloatConstant) interface{} {
+	if expr.Type() == symbolic.Float32Type {
+		return zt.ctx.FromFloat32(float32(expr.Value), zt.floatSort(expr.Type()))
+	}
+	return zt.ctx.FromFloat64(expr.Value, zt.floatSort(expr.Type()))
+}
+
+// floatSort возвращает сорт IEEE-754 для типа с плавающей точкой
+func (zt *Z3Translator) floatSort(exprType symbolic.ExpressionType) z3.Sort {
+	if exprType == symbolic.Float32Type {
+		return zt.ctx.FloatSort(8, 24)
+	}
+	return zt.ctx.FloatSort(11, 53)
+}
+
+// sort возвращает сорт Z3 для скалярного типа выражения
+func (zt *Z3Translator) sort(exprType symbolic.ExpressionType) (z3.Sort, bool) {
+	switch {
+	case exprType == symbolic.IntType, exprType == symbolic.RefType:
+		return zt.ctx.IntSort(), true
+	case exprType == symbolic.BoolType:
+		return zt.ctx.BoolSort(), true
+	case exprType.IsBitVector():
+		return zt.ctx.BVSort(exprType.BitWidth()), true
+	case exprType.IsFloat():
+		return zt.floatSort(exprType), true
+	default:
+		return z3.Sort{}, false
+	}
+}
+
 // VisitRef транслирует символьную ссылку в Z3
 func (zt *Z3Translator) VisitRef(expr *symbolic.Ref) interface{} {
+	// Символьная ссылка — целочисленная переменная, её равенство другим ссылкам решает Z3
+	if expr.Symbolic {
+		name := expr.String()
+		if v, exists := zt.vars[name]; exists {
+			return v
+		}
+		return zt.variable(name, zt.ctx.IntConst(name))
+	}
+
 	// Представляем ссылку как целочисленную константу с ID ссылки
 	return zt.ctx.FromInt(int64(expr.ID), zt.ctx.IntSort())
 }
@@ -97,13 +229,38 @@ func (zt *Z3Translator) VisitRef(expr *symbolic.Ref) interface{} {
 // VisitBinaryOperation транслирует бинарную операцию в Z3
 func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
 	// Транслировать левый и правый операнды
-	left := expr.Left.Accept(zt)
-	right := expr.Right.Accept(zt)
+	left := zt.translate(expr.Left)
+	right := zt.translate(expr.Right)
 
 	if left == nil || right == nil {
 		return nil
 	}
 
+	switch expr.Operator {
+	case symbolic.EQ:
+		return equal(left, right)
+	case symbolic.NE:
+		if eq := equal(left, right); eq != nil {
+			return eq.(z3.Bool).Not()
+		}
+		return nil
+	}
+
+	switch expr.Operator {
+	case symbolic.BIT_AND, symbolic.BIT_OR, symbolic.BIT_XOR, symbolic.BIT_AND_NOT:
+		return zt.translateBitwiseOperation(expr.Operator, left, right, expr.Left.Type())
+	case symbolic.SHL, symbolic.SHR:
+		return zt.translateShift(expr.Operator, left, expr.Left.Type(), right, expr.Right.Type())
+	}
+
+	if expr.Left.Type().IsFloat() {
+		return zt.translateFloatOperation(expr.Operator, left.(z3.Float), right.(z3.Float))
+	}
+
+	if expr.Left.Type().IsBitVector() {
+		return zt.translateBitVecOperation(expr.Operator, left.(z3.BV), right.(z3.BV), expr.Left.Type().IsSigned())
+	}
+
 	// В зависимости от оператора создать соответствующую Z3 операцию
 	switch expr.Operator {
 	case symbolic.ADD:
@@ -116,20 +273,6 @@ func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) int
 		return left.(z3.Int).Div(right.(z3.Int))
 	case symbolic.MOD:
 		return left.(z3.Int).Mod(right.(z3.Int))
-	case symbolic.EQ:
-		// Для равенства используем метод Eq
-		if expr.Left.Type() == symbolic.BoolType {
-			return left.(z3.Bool).Eq(right.(z3.Bool))
-		} else {
-			return left.(z3.Int).Eq(right.(z3.Int))
-		}
-	case symbolic.NE:
-		// Для неравенства используем Not от равенства
-		if expr.Left.Type() == symbolic.BoolType {
-			return left.(z3.Bool).Eq(right.(z3.Bool)).Not()
-		} else {
-			return left.(z3.Int).Eq(right.(z3.Int)).Not()
-		}
 	case symbolic.LT:
 		return left.(z3.Int).LT(right.(z3.Int))
 	case symbolic.LE:
@@ -144,12 +287,168 @@ func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) int
 	}
 }
 
+// translateBitVecOperation транслирует операцию над битовыми векторами.
+// Деление и остаток следуют Go: деление с отбрасыванием дробной части,
+// знак остатка совпадает со знаком делимого
+func (zt *Z3Translator) translateBitVecOperation(op symbolic.BinaryOperator, left, right z3.BV, signed bool) interface{} {
+	switch op {
+	case symbolic.ADD:
+		return left.Add(right)
+	case symbolic.SUB:
+		return left.Sub(right)
+	case symbolic.MUL:
+		return left.Mul(right)
+	case symbolic.DIV:
+		if signed {
+			return left.SDiv(right)
+		}
+		return left.UDiv(right)
+	case symbolic.MOD:
+		if signed {
+			return left.SRem(right)
+		}
+		return left.URem(right)
+	case symbolic.LT:
+		if signed {
+			return left.SLT(right)
+		}
+		return left.ULT(right)
+	case symbolic.LE:
+		if signed {
+			return left.SLE(right)
+		}
+		return left.ULE(right)
+	case symbolic.GT:
+		if signed {
+			return left.SGT(right)
+		}
+		return left.UGT(right)
+	case symbolic.GE:
+		if signed {
+			return left.SGE(right)
+		}
+		return left.UGE(right)
+	default:
+		fmt.Printf("Warning: неизвестный бинарный оператор: %v\n", op)
+		return nil
+	}
+}
+
+// translateFloatOperation транслирует операцию над числами с плавающей точкой.
+// Сравнения следуют IEEE-754: любое сравнение с NaN ложно
+func (zt *Z3Translator) translateFloatOperation(op symbolic.BinaryOperator, left, right z3.Float) interface{} {
+	switch op {
+	case symbolic.ADD:
+		return left.Add(right)
+	case symbolic.SUB:
+		return left.Sub(right)
+	case symbolic.MUL:
+		return left.Mul(right)
+	case symbolic.DIV:
+		return left.Div(right)
+	case symbolic.LT:
+		return left.LT(right)
+	case symbolic.LE:
+		return left.LE(right)
+	case symbolic.GT:
+		return left.GT(right)
+	case symbolic.GE:
+		return left.GE(right)
+	default:
+		fmt.Printf("Warning: неизвестный бинарный оператор: %v\n", op)
+		return nil
+	}
+}
+
+// translateBitwiseOperation транслирует побитовую операцию.
+// Неограниченные целые для этого представляются 64-битными векторами
+func (zt *Z3Translator) translateBitwiseOperation(op symbolic.BinaryOperator, left, right interface{}, exprType symbolic.ExpressionType) interface{} {
+	l, _ := toBV(left, exprType)
+	r, _ := toBV(right, exprType)
+
+	var result z3.BV
+	switch op {
+	case symbolic.BIT_AND:
+		result = l.And(r)
+	case symbolic.BIT_OR:
+		result = l.Or(r)
+	case symbolic.BIT_XOR:
+		result = l.Xor(r)
+	case symbolic.BIT_AND_NOT:
+		result = l.And(r.Not())
+	}
+	return fromBV(result, exprType)
+}
+
+// translateShift транслирует сдвиг по правилам Go: величина сдвига беззнаковая,
+// сдвиг на ширину типа и больше даёт 0 (или заполнение знаком для >> знаковых).
+// Для этого оба операнда расширяются до общей ширины, а результат усекается обратно
+func (zt *Z3Translator) translateShift(op symbolic.BinaryOperator, left interface{}, leftType symbolic.ExpressionType, count interface{}, countType symbolic.ExpressionType) interface{} {
+	l, leftWidth := toBV(left, leftType)
+	c, countWidth := toBV(count, countType)
+
+	width := leftWidth
+	if countWidth > width {
+		width = countWidth
+	}
+	signed := leftType.IsSigned()
+	l = resizeBV(l, leftWidth, width, signed)
+	c = resizeBV(c, countWidth, width, false)
+
+	var result z3.BV
+	switch {
+	case op == symbolic.SHL:
+		result = l.Lsh(c)
+	case signed:
+		result = l.SRsh(c)
+	default:
+		result = l.URsh(c)
+	}
+	return fromBV(resizeBV(result, width, leftWidth, signed), leftType)
+}
+
+// toBV представляет целое значение битовым вектором и возвращает его ширину
+func toBV(value interface{}, exprType symbolic.ExpressionType) (z3.BV, int) {
+	if exprType == symbolic.IntType {
+		return value.(z3.Int).ToBV(64), 64
+	}
+	return value.(z3.BV), exprType.BitWidth()
+}
+
+// fromBV возвращает битовый вектор к сорту типа exprType
+func fromBV(value z3.BV, exprType symbolic.ExpressionType) interface{} {
+	if exprType == symbolic.IntType {
+		return value.SToInt()
+	}
+	return value
+}
+
+// equal строит равенство двух Z3 значений одного сорта
+func equal(left, right interface{}) interface{} {
+	switch l := left.(type) {
+	case z3.Bool:
+		return l.Eq(right.(z3.Bool))
+	case z3.Int:
+		return l.Eq(right.(z3.Int))
+	case z3.BV:
+		return l.Eq(right.(z3.BV))
+	case z3.Array:
+		return l.Eq(right.(z3.Array))
+	case z3.Float:
+		// Равенство Go для чисел с плавающей точкой: NaN != NaN, +0 == -0
+		return l.IEEEEq(right.(z3.Float))
+	default:
+		fmt.Printf("Warning: неподдерживаемый сорт для сравнения: %T\n", left)
+		return nil
+	}
+}
+
 // VisitLogicalOperation транслирует логическую операцию в Z3
 func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
 	// 1. Транслировать все операнды
 	operands := make([]z3.Bool, len(expr.Operands))
 	for i, op := range expr.Operands {
-		result := op.Accept(zt)
+		result := zt.translate(op)
 		operands[i] = result.(z3.Bool)
 	}
 
@@ -185,14 +484,23 @@ func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) i
 }
 
 func (zt *Z3Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) interface{} {
-	operand := expr.Operand.Accept(zt)
+	operand := zt.translate(expr.Operand)
 	if operand == nil {
 		return nil
 	}
 
 	switch expr.Operator {
 	case symbolic.UNARY_MINUS:
+		if expr.Operand.Type().IsBitVector() {
+			return operand.(z3.BV).Neg()
+		}
+		if expr.Operand.Type().IsFloat() {
+			return operand.(z3.Float).Neg()
+		}
 		return operand.(z3.Int).Neg()
+	case symbolic.UNARY_COMPLEMENT:
+		bv, _ := toBV(operand, expr.Operand.Type())
+		return fromBV(bv.Not(), expr.Operand.Type())
 	case symbolic.UNARY_NOT:
 		return operand.(z3.Bool).Not()
 	default:
@@ -200,3 +508,116 @@ func (zt *Z3Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) inter
 		return nil
 	}
 }
+
+// VisitIte транслирует условное выражение в Z3 ite
+func (zt *Z3Translator) VisitIte(expr *symbolic.IteExpression) interface{} {
+	cond := zt.translate(expr.Cond)
+	then := zt.translate(expr.Then)
+	els := zt.translate(expr.Else)
+	if cond == nil || then == nil || els == nil {
+		return nil
+	}
+	return cond.(z3.Bool).IfThenElse(then.(z3.Value), els.(z3.Value))
+}
+
+// VisitArrayConstant транслирует константный массив в Z3 const-array
+func (zt *Z3Translator) VisitArrayConstant(expr *symbolic.ArrayConstant) interface{} {
+	value := zt.translate(expr.Default)
+	indexSort, ok := zt.sort(expr.IndexType)
+	if value == nil || !ok {
+		return nil
+	}
+	return zt.ctx.ConstArray(indexSort, value.(z3.Value))
+}
+
+// VisitArraySelect транслирует чтение элемента массива в Z3 select
+func (zt *Z3Translator) VisitArraySelect(expr *symbolic.ArraySelect) interface{} {
+	array := zt.translate(expr.Array)
+	index := zt.translate(expr.Index)
+	if array == nil || index == nil {
+		return nil
+	}
+	return array.(z3.Array).Select(index.(z3.Value))
+}
+
+// VisitArrayStore транслирует запись в массив в Z3 store
+func (zt *Z3Translator) VisitArrayStore(expr *symbolic.ArrayStore) interface{} {
+	array := zt.translate(expr.Array)
+	index := zt.translate(expr.Index)
+	value := zt.translate(expr.Value)
+	if array == nil || index == nil || value == nil {
+		return nil
+	}
+	return array.(z3.Array).Store(index.(z3.Value), value.(z3.Value))
+}
+
+// VisitConversion транслирует преобразование между числовыми типами
+func (zt *Z3Translator) VisitConversion(expr *symbolic.Conversion) interface{} {
+	operand := zt.translate(expr.Operand)
+	if operand == nil {
+		return nil
+	}
+
+	from, to := expr.Operand.Type(), expr.Type()
+	switch {
+	case from == to:
+		return operand
+	case from.IsFloat() && to.IsFloat():
+		return operand.(z3.Float).ToFloat(zt.floatSort(to))
+	case to.IsFloat():
+		return zt.integerToFloat(operand, from, to)
+	case from.IsFloat():
+		return zt.floatToInteger(operand.(z3.Float), to)
+	case from == symbolic.IntType:
+		return operand.(z3.Int).ToBV(to.BitWidth())
+	case to == symbolic.IntType:
+		if from.IsSigned() {
+			return operand.(z3.BV).SToInt()
+		}
+		return operand.(z3.BV).UToInt()
+	default:
+		return resizeBV(operand.(z3.BV), from.BitWidth(), to.BitWidth(), from.IsSigned())
+	}
+}
+
+// resizeBV меняет ширину битового вектора: усекает старшие биты
+// либо расширяет знаком или нулями в зависимости от знаковости исходного типа
+func resizeBV(value z3.BV, from, to int, signed bool) z3.BV {
+	switch {
+	case to < from:
+		return value.Extract(to-1, 0)
+	case to > from && signed:
+		return value.SignExtend(to - from)
+	case to > from:
+		return value.ZeroExtend(to - from)
+	default:
+		return value
+	}
+}
+
+// integerToFloat преобразует целое значение в число с плавающей точкой с округлением к ближайшему
+func (zt *Z3Translator) integerToFloat(operand interface{}, from, to symbolic.ExpressionType) z3.Float {
+	sort := zt.floatSort(to)
+	switch {
+	case from == symbolic.IntType:
+		return operand.(z3.Int).ToReal().ToFloat(sort)
+	case from.IsSigned():
+		return operand.(z3.BV).SToFloat(sort)
+	default:
+		return operand.(z3.BV).UToFloat(sort)
+	}
+}
+
+// floatToInteger преобразует число с плавающей точкой в целое,
+// отбрасывая дробную часть, как это делает Go
+func (zt *Z3Translator) floatToInteger(operand z3.Float, to symbolic.ExpressionType) interface{} {
+	truncated := operand.Round(z3.RoundToZero)
+	switch {
+	case to == symbolic.IntType:
+		return truncated.ToReal().ToInt()
+	case to.IsSigned():
+		return truncated.ToSBV(to.BitWidth())
+	default:
+		return truncated.ToUBV(to.BitWidth())
+	}
+}
@@ -0,0 +1,370 @@
+package translator
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// isSatisfiable транслирует условие и проверяет его выполнимость
+func isSatisfiable(t *testing.T, zt *Z3Translator, condition symbolic.SymbolicExpression) bool {
+	t.Helper()
+
+	z3Condition, err := zt.TranslateExpression(condition)
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+
+	solver := z3.NewSolver(zt.GetContext().(*z3.Context))
+	solver.Assert(z3Condition.(z3.Bool))
+	sat, err := solver.Check()
+	if err != nil {
+		t.Fatalf("Error checking satisfiability: %v", err)
+	}
+	return sat
+}
+
+// isValid проверяет, что условие истинно при любых значениях переменных
+func isValid(t *testing.T, zt *Z3Translator, condition symbolic.SymbolicExpression) bool {
+	t.Helper()
+	negation := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{condition}, symbolic.NOT)
+	return !isSatisfiable(t, zt, negation)
+}
+
+// TestBitVecOverflow тестирует переполнение целых фиксированной ширины
+func TestBitVecOverflow(t *testing.T) {
+	zt := NewZ3Translator()
+
+	x8 := symbolic.NewSymbolicVariable("x8", symbolic.Int8Type)
+	overflow := symbolic.NewBinaryOperation(
+		symbolic.NewBinaryOperation(x8, symbolic.NewBitVecConstant(1, symbolic.Int8Type), symbolic.ADD),
+		x8,
+		symbolic.LT,
+	)
+	if !isSatisfiable(t, zt, overflow) {
+		t.Errorf("Expected int8 overflow to be possible")
+	}
+
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	noOverflow := symbolic.NewBinaryOperation(
+		symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(1), symbolic.ADD),
+		x,
+		symbolic.LT,
+	)
+	if isSatisfiable(t, zt, noOverflow) {
+		t.Errorf("Expected unbounded int not to overflow")
+	}
+}
+
+// TestBitVecDivision тестирует знаковое и беззнаковое деление и остаток по правилам Go
+func TestBitVecDivision(t *testing.T) {
+	zt := NewZ3Translator()
+
+	cases := []struct {
+		left, right, expected int64
+		exprType              symbolic.ExpressionType
+		op                    symbolic.BinaryOperator
+	}{
+		{-7, 2, -7 / 2, symbolic.Int32Type, symbolic.DIV},
+		{-7, 2, -7 % 2, symbolic.Int32Type, symbolic.MOD},
+		{7, -2, 7 % -2, symbolic.Int64Type, symbolic.MOD},
+		{200, 3, 200 / 3, symbolic.Uint8Type, symbolic.DIV},
+		{250, 7, 250 % 7, symbolic.Uint8Type, symbolic.MOD},
+		{-128, -1, -128, symbolic.Int8Type, symbolic.DIV},
+	}
+
+	for _, c := range cases {
+		operation := symbolic.NewBinaryOperation(
+			symbolic.NewBitVecConstant(c.left, c.exprType),
+			symbolic.NewBitVecConstant(c.right, c.exprType),
+			c.op,
+		)
+		condition := symbolic.NewBinaryOperation(operation, symbolic.NewBitVecConstant(c.expected, c.exprType), symbolic.EQ)
+		if !isValid(t, zt, condition) {
+			t.Errorf("Expected %s == %d", operation.String(), c.expected)
+		}
+	}
+}
+
+// TestBitVecComparisons тестирует знаковые и беззнаковые сравнения
+func TestBitVecComparisons(t *testing.T) {
+	zt := NewZ3Translator()
+
+	unsigned := symbolic.NewBinaryOperation(
+		symbolic.NewBitVecConstant(255, symbolic.Uint8Type),
+		symbolic.NewBitVecConstant(1, symbolic.Uint8Type),
+		symbolic.GT,
+	)
+	if !isValid(t, zt, unsigned) {
+		t.Errorf("Expected uint8(255) > 1")
+	}
+
+	signed := symbolic.NewBinaryOperation(
+		symbolic.NewBitVecConstant(-1, symbolic.Int8Type),
+		symbolic.NewBitVecConstant(1, symbolic.Int8Type),
+		symbolic.LT,
+	)
+	if !isValid(t, zt, signed) {
+		t.Errorf("Expected int8(-1) < 1")
+	}
+}
+
+// TestConversion тестирует усечение и расширение при преобразованиях
+func TestConversion(t *testing.T) {
+	zt := NewZ3Translator()
+
+	cases := []struct {
+		value    int64
+		from, to symbolic.ExpressionType
+		expected int64
+	}{
+		{-1, symbolic.Int8Type, symbolic.Uint8Type, 255},
+		{-1, symbolic.Int8Type, symbolic.Int64Type, -1},
+		{255, symbolic.Uint8Type, symbolic.Int32Type, 255},
+		{300, symbolic.Int32Type, symbolic.Uint8Type, 44},
+	}
+
+	for _, c := range cases {
+		conversion := symbolic.NewConversion(symbolic.NewBitVecConstant(c.value, c.from), c.to)
+		condition := symbolic.NewBinaryOperation(conversion, symbolic.NewBitVecConstant(c.expected, c.to), symbolic.EQ)
+		if !isValid(t, zt, condition) {
+			t.Errorf("Expected %s == %d", conversion.String(), c.expected)
+		}
+	}
+}
+
+// TestBitwiseOperations тестирует побитовые операции и сдвиги с семантикой Go
+func TestBitwiseOperations(t *testing.T) {
+	zt := NewZ3Translator()
+
+	cases := []struct {
+		left     symbolic.SymbolicExpression
+		right    symbolic.SymbolicExpression
+		op       symbolic.BinaryOperator
+		expected symbolic.SymbolicExpression
+	}{
+		{symbolic.NewBitVecConstant(12, symbolic.Int32Type), symbolic.NewBitVecConstant(10, symbolic.Int32Type), symbolic.BIT_AND, symbolic.NewBitVecConstant(12&10, symbolic.Int32Type)},
+		{symbolic.NewBitVecConstant(12, symbolic.Int32Type), symbolic.NewBitVecConstant(10, symbolic.Int32Type), symbolic.BIT_OR, symbolic.NewBitVecConstant(12|10, symbolic.Int32Type)},
+		{symbolic.NewBitVecConstant(12, symbolic.Int32Type), symbolic.NewBitVecConstant(10, symbolic.Int32Type), symbolic.BIT_XOR, symbolic.NewBitVecConstant(12^10, symbolic.Int32Type)},
+		{symbolic.NewIntConstant(6), symbolic.NewIntConstant(3), symbolic.BIT_AND_NOT, symbolic.NewIntConstant(6 &^ 3)},
+		{symbolic.NewBitVecConstant(-128, symbolic.Int8Type), symbolic.NewBitVecConstant(10, symbolic.Uint64Type), symbolic.SHR, symbolic.NewBitVecConstant(-1, symbolic.Int8Type)},
+		{symbolic.NewBitVecConstant(128, symbolic.Uint8Type), symbolic.NewBitVecConstant(10, symbolic.Uint64Type), symbolic.SHR, symbolic.NewBitVecConstant(0, symbolic.Uint8Type)},
+		{symbolic.NewBitVecConstant(1, symbolic.Uint8Type), symbolic.NewBitVecConstant(8, symbolic.Uint8Type), symbolic.SHL, symbolic.NewBitVecConstant(0, symbolic.Uint8Type)},
+		{symbolic.NewBitVecConstant(1, symbolic.Uint8Type), symbolic.NewBitVecConstant(256, symbolic.Uint16Type), symbolic.SHL, symbolic.NewBitVecConstant(0, symbolic.Uint8Type)},
+		{symbolic.NewBitVecConstant(3, symbolic.Int64Type), symbolic.NewBitVecConstant(2, symbolic.Uint8Type), symbolic.SHL, symbolic.NewBitVecConstant(12, symbolic.Int64Type)},
+		{symbolic.NewIntConstant(-8), symbolic.NewIntConstant(1), symbolic.SHR, symbolic.NewIntConstant(-4)},
+	}
+
+	for _, c := range cases {
+		operation := symbolic.NewBinaryOperation(c.left, c.right, c.op)
+		condition := symbolic.NewBinaryOperation(operation, c.expected, symbolic.EQ)
+		if !isValid(t, zt, condition) {
+			t.Errorf("Expected %s == %s", operation.String(), c.expected.String())
+		}
+	}
+}
+
+// TestComplement тестирует побитовое дополнение
+func TestComplement(t *testing.T) {
+	zt := NewZ3Translator()
+
+	x := symbolic.NewSymbolicVariable("x", symbolic.Int64Type)
+	complement := symbolic.NewUnaryOperation(x, symbolic.UNARY_COMPLEMENT)
+	condition := symbolic.NewBinaryOperation(
+		symbolic.NewBinaryOperation(x, complement, symbolic.BIT_AND),
+		symbolic.NewBitVecConstant(0, symbolic.Int64Type),
+		symbolic.EQ,
+	)
+	if !isValid(t, zt, condition) {
+		t.Errorf("Expected x & ^x == 0")
+	}
+
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	intCondition := symbolic.NewBinaryOperation(
+		symbolic.NewUnaryOperation(y, symbolic.UNARY_COMPLEMENT),
+		symbolic.NewBinaryOperation(symbolic.NewUnaryOperation(y, symbolic.UNARY_MINUS), symbolic.NewIntConstant(1), symbolic.SUB),
+		symbolic.EQ,
+	)
+	if !isSatisfiable(t, zt, intCondition) {
+		t.Errorf("Expected ^y == -y - 1 to be satisfiable")
+	}
+}
+
+// TestFloatSemantics тестирует арифметику IEEE-754 с округлением к ближайшему чётному
+func TestFloatSemantics(t *testing.T) {
+	zt := NewZ3Translator()
+
+	sum := symbolic.NewBinaryOperation(
+		symbolic.NewFloatConstant(0.1, symbolic.Float64Type),
+		symbolic.NewFloatConstant(0.2, symbolic.Float64Type),
+		symbolic.ADD,
+	)
+	if !isValid(t, zt, symbolic.NewBinaryOperation(sum, symbolic.NewFloatConstant(0.30000000000000004, symbolic.Float64Type), symbolic.EQ)) {
+		t.Errorf("Expected 0.1 + 0.2 to be computed as in Go")
+	}
+	if !isValid(t, zt, symbolic.NewBinaryOperation(sum, symbolic.NewFloatConstant(0.3, symbolic.Float64Type), symbolic.NE)) {
+		t.Errorf("Expected 0.1 + 0.2 != 0.3")
+	}
+
+	big := symbolic.NewFloatConstant(16777216, symbolic.Float32Type)
+	rounded := symbolic.NewBinaryOperation(big, symbolic.NewFloatConstant(1, symbolic.Float32Type), symbolic.ADD)
+	if !isValid(t, zt, symbolic.NewBinaryOperation(rounded, big, symbolic.EQ)) {
+		t.Errorf("Expected float32(16777216) + 1 to round back to 16777216")
+	}
+}
+
+// TestFloatNaN тестирует сравнения с NaN и бесконечностями
+func TestFloatNaN(t *testing.T) {
+	zt := NewZ3Translator()
+
+	x := symbolic.NewSymbolicVariable("f", symbolic.Float64Type)
+	if !isSatisfiable(t, zt, symbolic.NewBinaryOperation(x, x, symbolic.NE)) {
+		t.Errorf("Expected x != x to be satisfiable for NaN")
+	}
+
+	plusOne := symbolic.NewBinaryOperation(x, symbolic.NewFloatConstant(1, symbolic.Float64Type), symbolic.ADD)
+	if !isSatisfiable(t, zt, symbolic.NewBinaryOperation(plusOne, x, symbolic.EQ)) {
+		t.Errorf("Expected x + 1 == x to be satisfiable")
+	}
+
+	zero := symbolic.NewFloatConstant(0, symbolic.Float64Type)
+	negZero := symbolic.NewUnaryOperation(zero, symbolic.UNARY_MINUS)
+	if !isValid(t, zt, symbolic.NewBinaryOperation(zero, negZero, symbolic.EQ)) {
+		t.Errorf("Expected +0 == -0")
+	}
+}
+
+// TestFloatConversion тестирует преобразования между целыми и числами с плавающей точкой
+func TestFloatConversion(t *testing.T) {
+	zt := NewZ3Translator()
+
+	truncated := symbolic.NewConversion(symbolic.NewFloatConstant(-2.7, symbolic.Float64Type), symbolic.Int64Type)
+	if !isValid(t, zt, symbolic.NewBinaryOperation(truncated, symbolic.NewBitVecConstant(-2, symbolic.Int64Type), symbolic.EQ)) {
+		t.Errorf("Expected int64(-2.7) == -2")
+	}
+
+	widened := symbolic.NewConversion(symbolic.NewBitVecConstant(-3, symbolic.Int32Type), symbolic.Float64Type)
+	if !isValid(t, zt, symbolic.NewBinaryOperation(widened, symbolic.NewFloatConstant(-3, symbolic.Float64Type), symbolic.EQ)) {
+		t.Errorf("Expected float64(int32(-3)) == -3")
+	}
+}
+
+// TestIte тестирует трансляцию условного выражения
+func TestIte(t *testing.T) {
+	zt := NewZ3Translator()
+
+	x := symbolic.NewSymbolicVariable("x", symbolic.Int64Type)
+	zero := symbolic.NewBitVecConstant(0, symbolic.Int64Type)
+	abs := symbolic.NewIteExpression(
+		symbolic.NewBinaryOperation(x, zero, symbolic.LT),
+		symbolic.NewUnaryOperation(x, symbolic.UNARY_MINUS),
+		x,
+	)
+	if abs.Type() != symbolic.Int64Type {
+		t.Errorf("Expected ite type int64, got %s", abs.Type().String())
+	}
+
+	// |x| < 0 достижимо только для минимального значения int64
+	negative := symbolic.NewBinaryOperation(abs, zero, symbolic.LT)
+	if !isSatisfiable(t, zt, negative) {
+		t.Errorf("Expected |MinInt64| < 0 to be satisfiable")
+	}
+
+	notMin := symbolic.NewBinaryOperation(x, symbolic.NewBitVecConstant(-1<<63, symbolic.Int64Type), symbolic.NE)
+	condition := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{negative, notMin}, symbolic.AND)
+	if isSatisfiable(t, zt, condition) {
+		t.Errorf("Expected |x| >= 0 for x != MinInt64")
+	}
+}
+
+// TestArrayTheory тестирует чтение и запись символьных массивов
+func TestArrayTheory(t *testing.T) {
+	zt := NewZ3Translator()
+
+	arr := symbolic.NewArrayVariable("arr", symbolic.Int64Type, symbolic.Int64Type)
+	i := symbolic.NewSymbolicVariable("i", symbolic.Int64Type)
+	j := symbolic.NewSymbolicVariable("j", symbolic.Int64Type)
+	value := symbolic.NewBitVecConstant(42, symbolic.Int64Type)
+
+	stored := symbolic.NewArrayStore(arr, i, value)
+	read := symbolic.NewArraySelect(stored, j)
+	if read.Type() != symbolic.Int64Type {
+		t.Fatalf("Expected select type int64, got %s", read.Type().String())
+	}
+
+	// Если i == j, то прочитанное значение равно записанному
+	sameIndex := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(i, j, symbolic.EQ),
+		symbolic.NewBinaryOperation(read, value, symbolic.NE),
+	}, symbolic.AND)
+	if isSatisfiable(t, zt, sameIndex) {
+		t.Errorf("Expected store/select on the same index to return the stored value")
+	}
+
+	// При разных индексах значение может отличаться
+	otherIndex := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(i, j, symbolic.NE),
+		symbolic.NewBinaryOperation(read, value, symbolic.NE),
+	}, symbolic.AND)
+	if !isSatisfiable(t, zt, otherIndex) {
+		t.Errorf("Expected select on another index to be unconstrained")
+	}
+}
+
+// TestArrayConstant тестирует инициализацию константного массива
+func TestArrayConstant(t *testing.T) {
+	zt := NewZ3Translator()
+
+	zeros := symbolic.NewArrayConstant(symbolic.IntType, symbolic.NewBoolConstant(false))
+	k := symbolic.NewSymbolicVariable("k", symbolic.IntType)
+	updated := symbolic.NewArrayStore(zeros, symbolic.NewIntConstant(3), symbolic.NewBoolConstant(true))
+
+	condition := symbolic.NewBinaryOperation(symbolic.NewArraySelect(updated, k), symbolic.NewBoolConstant(true), symbolic.EQ)
+	notThree := symbolic.NewBinaryOperation(k, symbolic.NewIntConstant(3), symbolic.NE)
+	if isSatisfiable(t, zt, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{condition, notThree}, symbolic.AND)) {
+		t.Errorf("Expected only index 3 to hold true")
+	}
+	if !isSatisfiable(t, zt, condition) {
+		t.Errorf("Expected index 3 to hold true")
+	}
+}
+
+// TestTranslationCache тестирует повторное использование трансляции общих подвыражений
+func TestTranslationCache(t *testing.T) {
+	zt := NewZ3Translator()
+	sum := func() symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(symbolic.NewSymbolicVariable("x", symbolic.IntType), symbolic.NewIntConstant(1), symbolic.ADD)
+	}
+
+	lower := symbolic.NewBinaryOperation(sum(), symbolic.NewIntConstant(0), symbolic.GT)
+	upper := symbolic.NewBinaryOperation(sum(), symbolic.NewIntConstant(5), symbolic.LT)
+	if !isSatisfiable(t, zt, lower) {
+		t.Errorf("Expected x + 1 > 0 to be satisfiable")
+	}
+	if stats := zt.Stats(); stats.Hits != 0 || stats.Misses != 5 {
+		t.Errorf("Expected 0 hits and 5 misses, got %+v", stats)
+	}
+
+	// Подвыражение x + 1 берётся из кэша, а x и 1 внутри него не транслируются
+	if !isSatisfiable(t, zt, upper) {
+		t.Errorf("Expected x + 1 < 5 to be satisfiable")
+	}
+	if stats := zt.Stats(); stats.Hits != 1 || stats.Misses != 7 {
+		t.Errorf("Expected 1 hit and 7 misses, got %+v", stats)
+	}
+
+	both := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{lower, upper, symbolic.NewBinaryOperation(sum(), symbolic.NewIntConstant(5), symbolic.GE)}, symbolic.AND)
+	if isSatisfiable(t, zt, both) {
+		t.Errorf("Expected cached subterms to keep their meaning")
+	}
+
+	zt.Reset()
+	if stats := zt.Stats(); stats != (CacheStats{}) {
+		t.Errorf("Expected Reset to clear statistics, got %+v", stats)
+	}
+	if !isSatisfiable(t, zt, lower) || zt.Stats().Hits != 0 {
+		t.Errorf("Expected Reset to clear the cache, got %+v", zt.Stats())
+	}
+}